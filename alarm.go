@@ -0,0 +1,116 @@
+package gotapo
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+)
+
+const (
+	// AlarmTypeSiren is alarm sound like siren
+	AlarmTypeSiren = "0"
+
+	// AlarmTypeTone is alarm sound like a bip
+	AlarmTypeTone = "1"
+)
+
+// AlarmConfig is config of alarm (sound and light) on detection.
+// Empty AlarmType, LightType, Volume and zero Duration are not sent to cam
+type AlarmConfig struct {
+	Enabled   bool
+	Sound     bool
+	Light     bool
+	AlarmType string
+	LightType string
+	Volume    string
+	Duration  time.Duration
+}
+
+// type alarm config return (inside multipleRequest)
+type alarmInfoRet struct {
+	MsgAlarm struct {
+		Chn1MsgAlarmInfo struct {
+			SoundAlarmEnabled string   `json:"sound_alarm_enabled"`
+			LightAlarmEnabled string   `json:"light_alarm_enabled"`
+			AlarmType         string   `json:"alarm_type"`
+			LightType         string   `json:"light_type"`
+			AlarmMode         []string `json:"alarm_mode"`
+			Enabled           string   `json:"enabled"`
+			AlarmVolume       string   `json:"alarm_volume"`
+			AlarmDuration     string   `json:"alarm_duration"`
+		} `json:"chn1_msg_alarm_info"`
+	} `json:"msg_alarm"`
+}
+
+// GetAlarm read alarm config of cam
+func (o *Tapo) GetAlarm() (AlarmConfig, error) {
+	result, err := o.multiple(lastAlarmInfoTemplate())
+	if err != nil {
+		return AlarmConfig{}, err
+	}
+	ret := new(alarmInfoRet)
+	if err := json.Unmarshal(result.Result.Responses[0].Result, ret); err != nil {
+		return AlarmConfig{}, ErrResponse
+	}
+	v := ret.MsgAlarm.Chn1MsgAlarmInfo
+	c := AlarmConfig{
+		Enabled:   sBool(v.Enabled),
+		Sound:     sBool(v.SoundAlarmEnabled),
+		Light:     sBool(v.LightAlarmEnabled),
+		AlarmType: v.AlarmType,
+		LightType: v.LightType,
+		Volume:    v.AlarmVolume,
+	}
+	for _, mode := range v.AlarmMode {
+		c.Sound = c.Sound || mode == "sound"
+		c.Light = c.Light || mode == "light"
+	}
+	if d, err := strconv.Atoi(v.AlarmDuration); err == nil {
+		c.Duration = time.Duration(d) * time.Second
+	}
+	return c, nil
+}
+
+// SetAlarm write full alarm config to cam
+func (o *Tapo) SetAlarm(c AlarmConfig) error {
+	if c.Enabled && !c.Sound && !c.Light {
+		return errors.New("gotapo: alarm enabled without sound and light")
+	}
+	list := []string{}
+	if c.Sound {
+		list = append(list, "sound")
+	}
+	if c.Light {
+		list = append(list, "light")
+	}
+	duration := ""
+	if c.Duration > 0 {
+		duration = strconv.Itoa(int(c.Duration.Round(time.Second) / time.Second))
+	}
+	return o.apply(
+		alarmTemplate(
+			c.AlarmType,
+			list,
+			new(Types).xBool(c.Enabled).Default,
+			c.LightType,
+			c.Volume,
+			duration,
+			new(Types).xBool(c.Sound).Default,
+			new(Types).xBool(c.Light).Default,
+		),
+	)
+}
+
+// UpdateAlarm read alarm config, change it by fn and write back.
+// Fields not changed by fn are kept as on cam. Updates are one by one
+func (o *Tapo) UpdateAlarm(fn func(c *AlarmConfig)) error {
+	o.config.Lock()
+	defer o.config.Unlock()
+	c, err := o.GetAlarm()
+	if err != nil {
+		return err
+	}
+	fn(&c)
+	return o.SetAlarm(c)
+}
@@ -0,0 +1,68 @@
+package gotapo_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/KusoKaihatsuSha/gotapo"
+)
+
+func TestUpdateAlarmConcurrent(t *testing.T) {
+	_, c := connect(t, true)
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := c.UpdateAlarm(func(a *gotapo.AlarmConfig) {
+				a.Duration += time.Second
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	a, err := c.GetAlarm()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Duration != 10*time.Second {
+		t.Fatalf("duration %v, want 10s (lost updates)", a.Duration)
+	}
+}
+
+func TestSetAlarmFlags(t *testing.T) {
+	s, c := connect(t, true)
+	s.SetValue("msg_alarm", "chn1_msg_alarm_info", "sound_alarm_enabled", "on")
+	s.SetValue("msg_alarm", "chn1_msg_alarm_info", "light_alarm_enabled", "on")
+	err := c.UpdateAlarm(func(a *gotapo.AlarmConfig) {
+		a.Light = false
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := s.Value("msg_alarm", "chn1_msg_alarm_info", "light_alarm_enabled"); v != "off" {
+		t.Fatalf("light_alarm_enabled %v, want off", v)
+	}
+	a, err := c.GetAlarm()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Light || !a.Sound {
+		t.Fatalf("alarm %+v, want only sound", a)
+	}
+}
+
+func TestAlarmModeKeepType(t *testing.T) {
+	s, c := connect(t, true)
+	s.SetValue("msg_alarm", "chn1_msg_alarm_info", "alarm_type", gotapo.AlarmTypeTone)
+	c.Elements.AlarmMode.On()
+	if v := s.Value("msg_alarm", "chn1_msg_alarm_info", "alarm_type"); v != gotapo.AlarmTypeTone {
+		t.Fatalf("alarm type %v, want kept %v", v, gotapo.AlarmTypeTone)
+	}
+	if v := s.Value("msg_alarm", "chn1_msg_alarm_info", "enabled"); v != "on" {
+		t.Fatalf("alarm %v, want on", v)
+	}
+}
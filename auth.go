@@ -0,0 +1,105 @@
+package gotapo
+
+import (
+	"errors"
+)
+
+// AuthMode is method of login to cam
+type AuthMode int
+
+const (
+	// AuthAuto check type of login by cam. If login with user failed
+	// trying "admin" (password of Tapo cloud account)
+	AuthAuto AuthMode = iota
+
+	// AuthLegacy is camera account with hash MD5 (firmware < 1.3.9)
+	AuthLegacy
+
+	// AuthSecure is camera account with hash SHA-256, nonce and encrypted requests
+	AuthSecure
+
+	// AuthAdmin is "admin" with password of Tapo cloud account. Type of hash checked by cam
+	AuthAdmin
+)
+
+// user of cloud account
+const adminUser = "admin"
+
+func (m AuthMode) String() string {
+	switch m {
+	case AuthAuto:
+		return "auto"
+	case AuthLegacy:
+		return "legacy"
+	case AuthSecure:
+		return "secure"
+	case AuthAdmin:
+		return "admin"
+	}
+	return "unknown"
+}
+
+// WithAuthMode set method of login (default AuthAuto)
+func WithAuthMode(mode AuthMode) Option {
+	return func(o *Tapo) {
+		o.authMode = mode
+	}
+}
+
+// AuthInfo is result of last login to cam
+type AuthInfo struct {
+	Mode     AuthMode // requested mode
+	Method   AuthMode // succeeded method (AuthLegacy, AuthSecure or AuthAdmin), AuthAuto if not logged
+	Secure   bool     // hash SHA-256 with nonce, requests encrypted
+	User     string   // login used
+	Fallback bool     // AuthAuto switched to "admin" after failed login with user
+	Err      error    // error of last login
+}
+
+// AuthInfo return which method of login worked (or why failed)
+func (o *Tapo) AuthInfo() AuthInfo {
+	o.state.Lock()
+	defer o.state.Unlock()
+	info := o.authInfo
+	info.Mode = o.authMode
+	return info
+}
+
+// Login used with cam
+func (o *Tapo) login() string {
+	if o.authUser != "" {
+		return o.authUser
+	}
+	return o.User
+}
+
+// Remember result of login. In AuthAuto after failed login with user
+// next login will be with "admin", but not if cam is near to lock
+func (o *Tapo) logged(err error) error {
+	o.state.Lock()
+	defer o.state.Unlock()
+	o.authInfo.Err = err
+	if err == nil {
+		o.authInfo.Method = AuthLegacy
+		if o.secure {
+			o.authInfo.Method = AuthSecure
+		}
+		if o.login() == adminUser {
+			o.authInfo.Method = AuthAdmin
+		}
+		o.authInfo.Secure = o.secure
+		o.authInfo.User = o.login()
+		return nil
+	}
+	o.authInfo.Method = AuthAuto
+	var loginErr *LoginError
+	if !errors.As(err, &loginErr) || loginErr.lastAttempt() {
+		return err
+	}
+	if o.authMode == AuthAuto && o.login() != adminUser {
+		p(`Authenticate failed. App will be trying with "admin" with next operation (password of your Tapo account).`)
+		o.authUser = adminUser
+		o.authInfo.Fallback = true
+	}
+	return err
+}
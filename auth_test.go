@@ -0,0 +1,83 @@
+package gotapo_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/KusoKaihatsuSha/gotapo"
+	"github.com/KusoKaihatsuSha/gotapo/gotapotest"
+)
+
+func TestLoginLegacy(t *testing.T) {
+	_, c := connect(t, false)
+	if _, err := c.Info(); err != nil {
+		t.Fatal(err)
+	}
+	info := c.AuthInfo()
+	if info.Method != gotapo.AuthLegacy || info.Secure || info.User != "cam" || info.Err != nil {
+		t.Fatalf("auth %+v, want legacy with cam", info)
+	}
+}
+
+func TestLoginSecure(t *testing.T) {
+	_, c := connect(t, true)
+	if _, err := c.Info(); err != nil {
+		t.Fatal(err)
+	}
+	info := c.AuthInfo()
+	if info.Method != gotapo.AuthSecure || !info.Secure || info.User != "cam" {
+		t.Fatalf("auth %+v, want secure with cam", info)
+	}
+}
+
+func TestLoginAdminFallback(t *testing.T) {
+	s := gotapotest.NewServer("admin", "cloud", true)
+	t.Cleanup(s.Close)
+	c := gotapo.Connect(s.Host, "cam", "cloud")
+	var err error
+	for i := 0; i < 2; i++ {
+		if _, err = c.Info(); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := c.AuthInfo()
+	if info.Method != gotapo.AuthAdmin || !info.Fallback || info.User != "admin" {
+		t.Fatalf("auth %+v, want fallback to admin", info)
+	}
+}
+
+func TestLoginStopBeforeLock(t *testing.T) {
+	s, _ := connect(t, true)
+	s.MaxAttempts = 3
+	c := gotapo.Connect(s.Host, "cam", "wrong", gotapo.WithAuthMode(gotapo.AuthSecure))
+	for i := 0; i < 5; i++ {
+		_, err := c.Info()
+		loginErr := new(gotapo.LoginError)
+		if !errors.As(err, &loginErr) {
+			t.Fatalf("error %v, want login error", err)
+		}
+		if loginErr.Locked() {
+			t.Fatalf("cam locked after %d attempts", i+1)
+		}
+	}
+	c = gotapo.Connect(s.Host, "cam", "secret")
+	if _, err := c.Info(); err != nil {
+		t.Fatalf("cam locked: %v", err)
+	}
+}
+
+func TestLoginLocked(t *testing.T) {
+	s, _ := connect(t, true)
+	s.MaxAttempts = 1
+	c := gotapo.Connect(s.Host, "cam", "wrong", gotapo.WithAuthMode(gotapo.AuthSecure))
+	for i := 0; i < 2; i++ {
+		_, err := c.Info()
+		loginErr := new(gotapo.LoginError)
+		if !errors.As(err, &loginErr) || !loginErr.Locked() || !errors.Is(err, gotapo.ErrLocked) {
+			t.Fatalf("error %v, want lock", err)
+		}
+	}
+}
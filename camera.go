@@ -0,0 +1,404 @@
+package gotapo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/KusoKaihatsuSha/gotapo/onvif"
+)
+
+// DeviceInfo is info about cam
+type DeviceInfo struct {
+	ID       string
+	Model    string
+	Name     string
+	Firmware string
+	Hardware string
+	Mac      string
+}
+
+// Preset is saved position of cam
+type Preset struct {
+	ID   string
+	Name string
+}
+
+// Camera is cam without dependency on backend (private API or ONVIF).
+// Backend returns ErrUnsupported for operations it haven't
+type Camera interface {
+	Info() (DeviceInfo, error)
+	SetPrivacy(on bool) error
+	SetLed(on bool) error
+	Move(x, y int) error
+	Presets() ([]Preset, error)
+	GotoPreset(id string) error
+	SetMotionDetection(on bool) error
+	Restart() error
+}
+
+var (
+	_ Camera = (*Tapo)(nil)
+	_ Camera = (*ONVIF)(nil)
+	_ Camera = (*Fallback)(nil)
+)
+
+// type device info return (inside multipleRequest)
+type deviceInfoRet struct {
+	DeviceInfo struct {
+		BasicInfo struct {
+			Ffs         bool   `json:"ffs"`
+			DeviceType  string `json:"device_type"`
+			DeviceModel string `json:"device_model"`
+			DeviceName  string `json:"device_name"`
+			DeviceInfo  string `json:"device_info"`
+			HwVersion   string `json:"hw_version"`
+			SwVersion   string `json:"sw_version"`
+			DeviceAlias string `json:"device_alias"`
+			Features    string `json:"features"`
+			Barcode     string `json:"barcode"`
+			Mac         string `json:"mac"`
+			DevID       string `json:"dev_id"`
+			OemID       string `json:"oem_id"`
+			HwDesc      string `json:"hw_desc"`
+		} `json:"basic_info"`
+	} `json:"device_info"`
+}
+
+// Info read info about cam
+func (o *Tapo) Info() (DeviceInfo, error) {
+	result, err := o.multiple(deviceInfoTemplate())
+	if err != nil {
+		return DeviceInfo{}, err
+	}
+	ret := new(deviceInfoRet)
+	if err := json.Unmarshal(result.Result.Responses[0].Result, ret); err != nil {
+		return DeviceInfo{}, ErrResponse
+	}
+	v := ret.DeviceInfo.BasicInfo
+	return DeviceInfo{
+		ID:       v.DevID,
+		Model:    v.DeviceModel,
+		Name:     v.DeviceAlias,
+		Firmware: v.SwVersion,
+		Hardware: v.HwVersion,
+		Mac:      v.Mac,
+	}, nil
+}
+
+// SetPrivacy turn privacy mode (with PrivacyPark too)
+func (o *Tapo) SetPrivacy(on bool) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.Elements.PrivacyMode.Value = on
+	return o.privacy(on)
+}
+
+// SetLed turn indicator diode
+func (o *Tapo) SetLed(on bool) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.Elements.Indicator.Value = on
+	return o.apply(setLedTemplate(new(Types).xBool(on).Default))
+}
+
+// Move cam on x, y degree
+func (o *Tapo) Move(x, y int) error {
+	return o.apply(movePositionTemplate(x, y))
+}
+
+// Presets read presets of cam (without privacy preset)
+func (o *Tapo) Presets() ([]Preset, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if err := o.loadPresets(); err != nil {
+		return nil, err
+	}
+	list := []Preset{}
+	for _, v := range o.presets {
+		list = append(list, Preset{ID: v.ID, Name: v.Name})
+	}
+	return list, nil
+}
+
+// GotoPreset move cam to preset
+func (o *Tapo) GotoPreset(id string) error {
+	return o.apply(nextPresetTemplate(id))
+}
+
+// SetMotionDetection turn motion detection without change of sensitivity
+func (o *Tapo) SetMotionDetection(on bool) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.Elements.DetectMode.Value = on
+	return o.Detectors.Motion.SetEnabled(on)
+}
+
+// Restart reboot cam
+func (o *Tapo) Restart() error {
+	return o.apply(rebootTemplate())
+}
+
+// ONVIFDegreesPerSecond is speed of cam on full velocity. Used by ONVIF.Move
+var ONVIFDegreesPerSecond = 30.0
+
+// ONVIF is Camera over ONVIF service of cam.
+// Privacy, led and detection are not in ONVIF (ErrUnsupported)
+type ONVIF struct {
+	Client  *onvif.Client
+	Timeout time.Duration
+	profile string
+	mu      sync.Mutex
+}
+
+// NewONVIF make Camera over ONVIF (port 2020) with account of cam
+func NewONVIF(host, user, password string) *ONVIF {
+	return &ONVIF{
+		Client:  onvif.New(host, user, password),
+		Timeout: 30 * time.Second,
+	}
+}
+
+func (o *ONVIF) ctx() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), o.Timeout)
+}
+
+// Token of first profile with PTZ
+func (o *ONVIF) ptzProfile(ctx context.Context) (string, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.profile != "" {
+		return o.profile, nil
+	}
+	profiles, err := o.Client.GetProfiles(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, v := range profiles {
+		if v.PTZToken != "" {
+			o.profile = v.Token
+			return o.profile, nil
+		}
+	}
+	return "", ErrUnsupported
+}
+
+// Info read info about cam
+func (o *ONVIF) Info() (DeviceInfo, error) {
+	ctx, cancel := o.ctx()
+	defer cancel()
+	info, err := o.Client.GetDeviceInformation(ctx)
+	if err != nil {
+		return DeviceInfo{}, err
+	}
+	return DeviceInfo{
+		ID:       info.SerialNumber,
+		Model:    info.Model,
+		Firmware: info.FirmwareVersion,
+		Hardware: info.HardwareID,
+	}, nil
+}
+
+// SetPrivacy is not in ONVIF
+func (o *ONVIF) SetPrivacy(on bool) error {
+	return ErrUnsupported
+}
+
+// SetLed is not in ONVIF
+func (o *ONVIF) SetLed(on bool) error {
+	return ErrUnsupported
+}
+
+// SetMotionDetection is not in ONVIF
+func (o *ONVIF) SetMotionDetection(on bool) error {
+	return ErrUnsupported
+}
+
+// Move cam on x, y degree. Approximately, by time of continuous move
+// with speed ONVIFDegreesPerSecond
+func (o *ONVIF) Move(x, y int) error {
+	if x == 0 && y == 0 {
+		return nil
+	}
+	ctx, cancel := o.ctx()
+	defer cancel()
+	profile, err := o.ptzProfile(ctx)
+	if err != nil {
+		return err
+	}
+	max := math.Max(math.Abs(float64(x)), math.Abs(float64(y)))
+	duration := time.Duration(max / ONVIFDegreesPerSecond * float64(time.Second))
+	v := onvif.Velocity{Pan: float64(x) / max, Tilt: float64(y) / max}
+	if err := o.Client.ContinuousMove(ctx, profile, v, duration); err != nil {
+		return err
+	}
+	select {
+	case <-time.After(duration):
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return o.Client.Stop(ctx, profile)
+}
+
+// Presets read PTZ presets of cam
+func (o *ONVIF) Presets() ([]Preset, error) {
+	ctx, cancel := o.ctx()
+	defer cancel()
+	profile, err := o.ptzProfile(ctx)
+	if err != nil {
+		return nil, err
+	}
+	presets, err := o.Client.GetPresets(ctx, profile)
+	if err != nil {
+		return nil, err
+	}
+	list := []Preset{}
+	for _, v := range presets {
+		list = append(list, Preset{ID: v.Token, Name: v.Name})
+	}
+	return list, nil
+}
+
+// GotoPreset move cam to preset
+func (o *ONVIF) GotoPreset(id string) error {
+	ctx, cancel := o.ctx()
+	defer cancel()
+	profile, err := o.ptzProfile(ctx)
+	if err != nil {
+		return err
+	}
+	return o.Client.GotoPreset(ctx, profile, id)
+}
+
+// Restart reboot cam
+func (o *ONVIF) Restart() error {
+	ctx, cancel := o.ctx()
+	defer cancel()
+	return o.Client.SystemReboot(ctx)
+}
+
+// Fallback is Camera which use Primary backend and Secondary if Primary
+// haven't operation (ErrUnsupported) or it can't connect or login to cam.
+// Reading and settings (repeat is harmless) are tried on Secondary also on
+// ErrResponse (unknown answer of firmware, like after change of protocol).
+// Move and Restart are tried on Secondary only if Primary not done them
+// (request not sent, refused or unsupported), not on ErrResponse or timeout.
+// IDs of presets differ between backends, so GotoPreset use backend
+// which returned last Presets
+type Fallback struct {
+	Primary   Camera
+	Secondary Camera
+	presets   Camera
+	mu        sync.Mutex
+}
+
+// NewFallback make Camera with fallback, like NewFallback(tapo, NewONVIF(...))
+func NewFallback(primary, secondary Camera) *Fallback {
+	return &Fallback{Primary: primary, Secondary: secondary}
+}
+
+// Error of backend after which action (Move, Restart) is tried on other
+// backend: action is not done by cam
+func actionFallbackError(err error) bool {
+	var fault *onvif.Fault
+	return notSent(err) ||
+		errors.Is(err, ErrUnsupported) ||
+		errors.Is(err, ErrInvalidCredentials) ||
+		errors.Is(err, ErrUserNotAuthorized) ||
+		(errors.As(err, &fault) && strings.HasSuffix(fault.Subcode, "NotAuthorized"))
+}
+
+// Error of backend after which reading or setting is tried on other backend
+func fallbackError(err error) bool {
+	return actionFallbackError(err) || errors.Is(err, ErrResponse)
+}
+
+// Run operation on Primary, on fallback error on Secondary. Backend which
+// done operation is returned
+func (o *Fallback) try(fn func(c Camera) error, fallback func(err error) bool) (Camera, error) {
+	err := fn(o.Primary)
+	if err == nil || !fallback(err) {
+		return o.Primary, err
+	}
+	errSecondary := fn(o.Secondary)
+	switch {
+	case errSecondary == nil:
+		return o.Secondary, nil
+	case errors.Is(errSecondary, ErrUnsupported):
+		return o.Primary, err
+	case errors.Is(err, ErrUnsupported):
+		return o.Secondary, errSecondary
+	}
+	return o.Primary, errors.Join(err, errSecondary)
+}
+
+// Info read info about cam
+func (o *Fallback) Info() (DeviceInfo, error) {
+	var info DeviceInfo
+	_, err := o.try(func(c Camera) (err error) {
+		info, err = c.Info()
+		return err
+	}, fallbackError)
+	return info, err
+}
+
+// SetPrivacy turn privacy mode
+func (o *Fallback) SetPrivacy(on bool) error {
+	_, err := o.try(func(c Camera) error { return c.SetPrivacy(on) }, fallbackError)
+	return err
+}
+
+// SetLed turn indicator diode
+func (o *Fallback) SetLed(on bool) error {
+	_, err := o.try(func(c Camera) error { return c.SetLed(on) }, fallbackError)
+	return err
+}
+
+// Move cam on x, y degree. Secondary only if Primary not done it
+func (o *Fallback) Move(x, y int) error {
+	_, err := o.try(func(c Camera) error { return c.Move(x, y) }, actionFallbackError)
+	return err
+}
+
+// Presets read presets of cam. Backend of presets is kept for GotoPreset
+func (o *Fallback) Presets() ([]Preset, error) {
+	var list []Preset
+	c, err := o.try(func(c Camera) (err error) {
+		list, err = c.Presets()
+		return err
+	}, fallbackError)
+	if err == nil {
+		o.mu.Lock()
+		o.presets = c
+		o.mu.Unlock()
+	}
+	return list, err
+}
+
+// GotoPreset move cam to preset. ID is from Presets
+// (of Primary if Presets was not read)
+func (o *Fallback) GotoPreset(id string) error {
+	o.mu.Lock()
+	c := o.presets
+	o.mu.Unlock()
+	if c == nil {
+		c = o.Primary
+	}
+	return c.GotoPreset(id)
+}
+
+// SetMotionDetection turn motion detection
+func (o *Fallback) SetMotionDetection(on bool) error {
+	_, err := o.try(func(c Camera) error { return c.SetMotionDetection(on) }, fallbackError)
+	return err
+}
+
+// Restart reboot cam. Secondary only if Primary not done it
+func (o *Fallback) Restart() error {
+	_, err := o.try(func(c Camera) error { return c.Restart() }, actionFallbackError)
+	return err
+}
@@ -0,0 +1,156 @@
+package gotapo_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/KusoKaihatsuSha/gotapo"
+	"github.com/KusoKaihatsuSha/gotapo/gotapotest"
+	"github.com/KusoKaihatsuSha/gotapo/onvif/onviftest"
+)
+
+// Fake ONVIF service and camera over it
+func connectONVIF(t *testing.T) (*onviftest.Server, *gotapo.ONVIF) {
+	t.Helper()
+	s := onviftest.NewServer("cam", "secret")
+	t.Cleanup(s.Close)
+	return s, gotapo.NewONVIF(s.Host, "cam", "secret")
+}
+
+func TestONVIFPresetsConcurrent(t *testing.T) {
+	s, c := connectONVIF(t)
+	wg := sync.WaitGroup{}
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			presets, err := c.Presets()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if len(presets) != 2 || presets[0].Name != "Door" {
+				t.Errorf("presets %+v", presets)
+			}
+		}()
+	}
+	wg.Wait()
+	if err := c.GotoPreset("2"); err != nil {
+		t.Fatal(err)
+	}
+	profiles := 0
+	for _, v := range s.Calls() {
+		if v == "GetProfiles" {
+			profiles++
+		}
+	}
+	if profiles != 1 {
+		t.Fatalf("profiles read %d times, want once", profiles)
+	}
+}
+
+func TestFallbackPresetsPinned(t *testing.T) {
+	s, _ := connect(t, true)
+	o, onvifCam := connectONVIF(t)
+	c := gotapo.NewFallback(gotapo.Connect(s.Host, "cam", "wrong"), onvifCam)
+	presets, err := c.Presets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(presets) != 2 || presets[1].ID != "2" {
+		t.Fatalf("presets %+v, want presets of ONVIF", presets)
+	}
+	if err := c.GotoPreset("2"); err != nil {
+		t.Fatal(err)
+	}
+	if gotos := o.Gotos(); len(gotos) != 1 || gotos[0] != "2" {
+		t.Fatalf("gotos of ONVIF %v, want [2]", gotos)
+	}
+}
+
+func TestFallbackNotSent(t *testing.T) {
+	s, _ := connect(t, true)
+	o, onvifCam := connectONVIF(t)
+	c := gotapo.NewFallback(gotapo.Connect(s.Host, "cam", "wrong"), onvifCam)
+	if err := c.Move(10, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Restart(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"ContinuousMove", "Stop", "SystemReboot"}
+	calls := o.Calls()
+	for _, name := range want {
+		found := false
+		for _, call := range calls {
+			found = found || call == name
+		}
+		if !found {
+			t.Fatalf("calls of ONVIF %v, want %v", calls, want)
+		}
+	}
+	if s.Reboots() != 0 || len(s.Moves()) != 0 {
+		t.Fatal("action on cam without login")
+	}
+}
+
+func TestFallbackNoRepeatMove(t *testing.T) {
+	s, c := connect(t, true)
+	o, onvifCam := connectONVIF(t)
+	s.SetValue("lens_mask", "lens_mask_info", "enabled", "on")
+	f := gotapo.NewFallback(c, onvifCam)
+	if err := f.Move(10, 0); !errors.Is(err, gotapo.ErrPrivacyOn) {
+		t.Fatalf("error %v, want privacy on", err)
+	}
+	if calls := o.Calls(); len(calls) != 0 {
+		t.Fatalf("calls of ONVIF %v, want none", calls)
+	}
+}
+
+func TestFallbackResponse(t *testing.T) {
+	s, c := connect(t, true)
+	o, onvifCam := connectONVIF(t)
+	f := gotapo.NewFallback(c, onvifCam)
+	s.Fail(gotapotest.FaultMalformed, 10)
+	if _, err := f.Info(); err != nil {
+		t.Fatal(err)
+	}
+	if calls := o.Calls(); len(calls) == 0 {
+		t.Fatal("info not read by ONVIF")
+	}
+	s.Fail(gotapotest.FaultMalformed, 10)
+	if err := f.Move(10, 0); !errors.Is(err, gotapo.ErrResponse) {
+		t.Fatalf("error %v, want response", err)
+	}
+	for _, call := range o.Calls() {
+		if call == "ContinuousMove" {
+			t.Fatal("move repeated by ONVIF")
+		}
+	}
+}
+
+func TestFallbackUnsupported(t *testing.T) {
+	s, c := connect(t, true)
+	_, onvifCam := connectONVIF(t)
+	f := gotapo.NewFallback(onvifCam, c)
+	if err := f.SetLed(false); err != nil {
+		t.Fatal(err)
+	}
+	if v := s.Value("led", "config", "enabled"); v != "off" {
+		t.Fatalf("led %v, want off", v)
+	}
+}
+
+func TestFallbackCamError(t *testing.T) {
+	s, c := connect(t, true)
+	o, onvifCam := connectONVIF(t)
+	s.SetValue("lens_mask", "lens_mask_info", "enabled", "on")
+	f := gotapo.NewFallback(c, onvifCam)
+	if err := f.GotoPreset("1"); !errors.Is(err, gotapo.ErrPrivacyOn) {
+		t.Fatalf("error %v, want privacy on", err)
+	}
+	if calls := o.Calls(); len(calls) != 0 {
+		t.Fatalf("calls of ONVIF %v, want none", calls)
+	}
+}
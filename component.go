@@ -0,0 +1,70 @@
+package gotapo
+
+import (
+	"encoding/json"
+)
+
+// type get list of components (capabilities) of cam
+type appComponentList struct {
+	Method string `json:"method"`
+	Data   struct {
+		Type struct {
+			Name string `json:"name"`
+		} `json:"app_component"`
+	} `json:"params"`
+}
+
+// type get list of components return (inside multipleRequest)
+type appComponentListRet struct {
+	AppComponent struct {
+		AppComponentList []struct {
+			Name    string `json:"name"`
+			Version int    `json:"version"`
+		} `json:"app_component_list"`
+	} `json:"app_component"`
+}
+
+func appComponentListTemplate(values ...any) appComponentList {
+	t := appComponentList{}
+	t.Method = "getAppComponentList"
+	t.Data.Type.Name = "app_component_list"
+	return t
+}
+
+// Components read components of cam with versions. Result cached
+func (o *Tapo) Components() (map[string]int, error) {
+	o.state.Lock()
+	components := o.components
+	o.state.Unlock()
+	if components != nil {
+		return components, nil
+	}
+	result, err := o.multiple(appComponentListTemplate())
+	if err != nil {
+		return nil, err
+	}
+	ret := new(appComponentListRet)
+	if err := json.Unmarshal(result.Result.Responses[0].Result, ret); err != nil {
+		return nil, ErrResponse
+	}
+	components = map[string]int{}
+	for _, v := range ret.AppComponent.AppComponentList {
+		components[v.Name] = v.Version
+	}
+	o.state.Lock()
+	o.components = components
+	o.state.Unlock()
+	return components, nil
+}
+
+// Check component of cam. Return ErrUnsupported if cam haven't it
+func (o *Tapo) supports(name string) error {
+	components, err := o.Components()
+	if err != nil {
+		return err
+	}
+	if _, ok := components[name]; !ok {
+		return ErrUnsupported
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package gotapo
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// CredentialProvider give login and password of cam at time of authentication.
+// Password is zeroised after hashing, so provider must return new slice every call
+type CredentialProvider interface {
+	Credentials() (user string, password []byte, err error)
+}
+
+// CredentialFunc is callback as CredentialProvider
+type CredentialFunc func() (user string, password []byte, err error)
+
+// Credentials call func
+func (f CredentialFunc) Credentials() (string, []byte, error) {
+	return f()
+}
+
+// EnvCredentials read login and password from environment variables
+func EnvCredentials(userVar, passwordVar string) CredentialProvider {
+	return CredentialFunc(func() (string, []byte, error) {
+		user, ok := os.LookupEnv(userVar)
+		if !ok {
+			return "", nil, fmt.Errorf("environment variable %s not set", userVar)
+		}
+		password, ok := os.LookupEnv(passwordVar)
+		if !ok {
+			return "", nil, fmt.Errorf("environment variable %s not set", passwordVar)
+		}
+		return user, []byte(password), nil
+	})
+}
+
+// FileCredentials read login (first line) and password (second line) from file.
+// File must be not accessible for group and others (0600)
+func FileCredentials(path string) CredentialProvider {
+	return CredentialFunc(func() (string, []byte, error) {
+		info, err := os.Stat(path)
+		if err != nil {
+			return "", nil, err
+		}
+		if info.Mode().Perm()&0o077 != 0 {
+			return "", nil, fmt.Errorf("file %s has mode %#o, want 0600", path, info.Mode().Perm())
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return "", nil, err
+		}
+		defer zero(b)
+		user, password, _ := bytes.Cut(b, []byte("\n"))
+		password, _, _ = bytes.Cut(password, []byte("\n"))
+		password = bytes.TrimSuffix(password, []byte("\r"))
+		if len(password) == 0 {
+			return "", nil, fmt.Errorf("file %s without password", path)
+		}
+		return strings.TrimSpace(string(user)), append([]byte{}, password...), nil
+	})
+}
+
+// WithCredentials set provider of login and password.
+// User and password of Connect are not used
+func WithCredentials(provider CredentialProvider) Option {
+	return func(o *Tapo) {
+		o.credentials = provider
+	}
+}
+
+// Get login and password from provider and keep only hashes
+func (o *Tapo) fetchCredentials() error {
+	if o.credentials == nil {
+		return nil
+	}
+	user, password, err := o.credentials.Credentials()
+	if err != nil {
+		return fmt.Errorf("gotapo: credentials: %w", err)
+	}
+	defer zero(password)
+	if len(password) == 0 {
+		return errors.New("gotapo: credentials: empty password")
+	}
+	md5Hash := o.hashedPasswordMD5
+	o.setPassword(password)
+	if user != o.User || md5Hash != o.hashedPasswordMD5 {
+		// new credentials have new attempts of login
+		o.User = user
+		o.resetLogin()
+	}
+	return nil
+}
+
+// Hash password for both types of login
+func (o *Tapo) setPassword(password []byte) {
+	o.hashedPasswordMD5 = fmt.Sprintf("%X", md5.Sum(password))
+	o.hashedPasswordSha256 = fmt.Sprintf("%X", sha256.Sum256(password))
+}
+
+// Overwrite secret in memory
+func zero(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -0,0 +1,85 @@
+package gotapo_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/KusoKaihatsuSha/gotapo"
+)
+
+func TestEnvCredentials(t *testing.T) {
+	t.Setenv("GOTAPO_TEST_USER", "cam")
+	t.Setenv("GOTAPO_TEST_PASSWORD", "secret")
+	user, password, err := gotapo.EnvCredentials("GOTAPO_TEST_USER", "GOTAPO_TEST_PASSWORD").Credentials()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user != "cam" || string(password) != "secret" {
+		t.Fatalf("credentials %q %q", user, password)
+	}
+	if _, _, err := gotapo.EnvCredentials("GOTAPO_TEST_NO_USER", "GOTAPO_TEST_PASSWORD").Credentials(); err == nil {
+		t.Fatal("user not set, want error")
+	}
+	if _, _, err := gotapo.EnvCredentials("GOTAPO_TEST_USER", "GOTAPO_TEST_NO_PASSWORD").Credentials(); err == nil {
+		t.Fatal("password not set, want error")
+	}
+}
+
+func TestFileCredentials(t *testing.T) {
+	dir := t.TempDir()
+	file := func(name, text string, mode os.FileMode) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(text), mode); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chmod(path, mode); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	for _, text := range []string{"cam\nsecret", "cam\nsecret\n", " cam \r\nsecret\r\n", "cam\nsecret\nother"} {
+		user, password, err := gotapo.FileCredentials(file("ok", text, 0o600)).Credentials()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if user != "cam" || string(password) != "secret" {
+			t.Fatalf("credentials %q %q from %q", user, password, text)
+		}
+	}
+	for _, text := range []string{"cam", "cam\n", "cam\n\nsecret"} {
+		if _, _, err := gotapo.FileCredentials(file("empty", text, 0o600)).Credentials(); err == nil {
+			t.Fatalf("file %q without password, want error", text)
+		}
+	}
+	if _, _, err := gotapo.FileCredentials(filepath.Join(dir, "none")).Credentials(); err == nil {
+		t.Fatal("file not exist, want error")
+	}
+	if runtime.GOOS == "windows" {
+		return
+	}
+	for _, mode := range []os.FileMode{0o640, 0o604, 0o644} {
+		if _, _, err := gotapo.FileCredentials(file("open", "cam\nsecret", mode)).Credentials(); err == nil {
+			t.Fatalf("file with mode %#o, want error", mode)
+		}
+	}
+}
+
+func TestCredentialFunc(t *testing.T) {
+	s, _ := connect(t, true)
+	c := gotapo.Connect(s.Host, "", "", gotapo.WithCredentials(gotapo.CredentialFunc(func() (string, []byte, error) {
+		return "cam", []byte("secret"), nil
+	})))
+	if _, err := c.Info(); err != nil {
+		t.Fatal(err)
+	}
+	errProvider := errors.New("vault is sealed")
+	c = gotapo.Connect(s.Host, "", "", gotapo.WithCredentials(gotapo.CredentialFunc(func() (string, []byte, error) {
+		return "", nil, errProvider
+	})))
+	if _, err := c.Info(); !errors.Is(err, errProvider) {
+		t.Fatalf("error %v, want error of provider", err)
+	}
+}
@@ -0,0 +1,405 @@
+package gotapo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+const (
+	// MaxLines is max count of lines for line-crossing detection
+	MaxLines = 4
+
+	// MaxPolygons is max count of areas for intrusion detection
+	MaxPolygons = 4
+
+	// MaxPolygonPoints is max count of points in area for intrusion detection
+	MaxPolygonPoints = 10
+)
+
+// LineDirection is direction of crossing line
+type LineDirection string
+
+const (
+	// LineAToB crossing from side A to side B
+	LineAToB LineDirection = "AtoB"
+
+	// LineBToA crossing from side B to side A
+	LineBToA LineDirection = "BtoA"
+
+	// LineBoth crossing in both directions
+	LineBoth LineDirection = "both"
+)
+
+// Point on coordinate grid of cam (0-MotionGrid)
+type Point struct {
+	X int
+	Y int
+}
+
+// Line for line-crossing detection
+type Line struct {
+	Start     Point
+	End       Point
+	Direction LineDirection
+}
+
+// Polygon is area for intrusion detection
+type Polygon struct {
+	Points []Point
+}
+
+// LineCrossing is config of line-crossing detection
+type LineCrossing struct {
+	Enabled     bool
+	Sensitivity int
+	Lines       []Line
+}
+
+// Intrusion is config of area intrusion detection
+type Intrusion struct {
+	Enabled     bool
+	Sensitivity int
+	Areas       []Polygon
+}
+
+// type get line-crossing/intrusion config
+type crossingConfig struct {
+	Method string                         `json:"method"`
+	Data   map[string]crossingConfigNames `json:"params"`
+}
+
+// type names of get config
+type crossingConfigNames struct {
+	Name  []string `json:"name"`
+	Table []string `json:"table"`
+}
+
+// type set line-crossing/intrusion config
+type crossingSet struct {
+	Method string                     `json:"method"`
+	Data   map[string]crossingSection `json:"params"`
+}
+
+// type values of line-crossing/intrusion config
+type crossingSection struct {
+	Detection       detectionInfo                  `json:"detection"`
+	DetectionRegion []map[string]map[string]string `json:"detection_region,omitempty"`
+}
+
+// type add rows of detection_region
+type crossingAdd struct {
+	Method string                                    `json:"method"`
+	Data   map[string]map[string][]map[string]string `json:"params"`
+}
+
+// type remove rows of detection_region
+type crossingRemove struct {
+	Method string                                    `json:"method"`
+	Data   map[string]map[string]map[string][]string `json:"params"`
+}
+
+// type get config return (inside multipleRequest)
+type crossingRet map[string]crossingSection
+
+func crossingConfigTemplate(values ...any) crossingConfig {
+	t := crossingConfig{}
+	t.Method = values[0].(string)
+	t.Data = map[string]crossingConfigNames{
+		values[1].(string): {
+			Name:  []string{"detection"},
+			Table: []string{"detection_region"},
+		},
+	}
+	return t
+}
+
+func crossingSetTemplate(values ...any) crossingSet {
+	t := crossingSet{}
+	t.Method = values[0].(string)
+	t.Data = map[string]crossingSection{
+		values[1].(string): values[2].(crossingSection),
+	}
+	return t
+}
+
+func crossingAddTemplate(values ...any) crossingAdd {
+	t := crossingAdd{}
+	t.Method = "add"
+	t.Data = map[string]map[string][]map[string]string{
+		values[0].(string): {
+			"detection_region": {values[1].(map[string]string)},
+		},
+	}
+	return t
+}
+
+func crossingRemoveTemplate(values ...any) crossingRemove {
+	t := crossingRemove{}
+	t.Method = "delete"
+	t.Data = map[string]map[string]map[string][]string{
+		values[0].(string): {
+			"detection_region": {"name": values[1].([]string)},
+		},
+	}
+	return t
+}
+
+func inGrid(pt Point) bool {
+	return pt.X >= 0 && pt.Y >= 0 && pt.X <= MotionGrid && pt.Y <= MotionGrid
+}
+
+// Validate check line inside grid with known direction
+func (l Line) Validate() error {
+	if !inGrid(l.Start) || !inGrid(l.End) {
+		return fmt.Errorf("gotapo: line %v-%v out of grid %d", l.Start, l.End, MotionGrid)
+	}
+	if l.Start == l.End {
+		return fmt.Errorf("gotapo: line %v-%v has zero length", l.Start, l.End)
+	}
+	switch l.Direction {
+	case LineAToB, LineBToA, LineBoth:
+	default:
+		return fmt.Errorf("gotapo: unknown line direction %q", l.Direction)
+	}
+	return nil
+}
+
+// Validate check polygon inside grid and not degenerate
+func (a Polygon) Validate() error {
+	if len(a.Points) < 3 || len(a.Points) > MaxPolygonPoints {
+		return fmt.Errorf("gotapo: area must have 3-%d points, got %d", MaxPolygonPoints, len(a.Points))
+	}
+	area := 0
+	for k, v := range a.Points {
+		if !inGrid(v) {
+			return fmt.Errorf("gotapo: point %v out of grid %d", v, MotionGrid)
+		}
+		next := a.Points[(k+1)%len(a.Points)]
+		area += v.X*next.Y - next.X*v.Y
+	}
+	if area == 0 {
+		return fmt.Errorf("gotapo: area %v has zero size", a.Points)
+	}
+	return nil
+}
+
+// Validate check config of line-crossing detection
+func (c LineCrossing) Validate() error {
+	if c.Sensitivity < 0 || c.Sensitivity > 100 {
+		return fmt.Errorf("gotapo: line-crossing sensitivity %d out of range 0-100", c.Sensitivity)
+	}
+	if len(c.Lines) > MaxLines {
+		return fmt.Errorf("gotapo: max %d lines, got %d", MaxLines, len(c.Lines))
+	}
+	for _, v := range c.Lines {
+		if err := v.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate check config of intrusion detection
+func (c Intrusion) Validate() error {
+	if c.Sensitivity < 0 || c.Sensitivity > 100 {
+		return fmt.Errorf("gotapo: intrusion sensitivity %d out of range 0-100", c.Sensitivity)
+	}
+	if len(c.Areas) > MaxPolygons {
+		return fmt.Errorf("gotapo: max %d areas, got %d", MaxPolygons, len(c.Areas))
+	}
+	for _, v := range c.Areas {
+		if err := v.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Read section of line-crossing/intrusion config
+func (o *Tapo) getCrossing(component, method, module string) (crossingSection, error) {
+	if err := o.supports(component); err != nil {
+		return crossingSection{}, err
+	}
+	result, err := o.multiple(crossingConfigTemplate(method, module))
+	if err != nil {
+		return crossingSection{}, err
+	}
+	ret := crossingRet{}
+	if err := json.Unmarshal(result.Result.Responses[0].Result, &ret); err != nil {
+		return crossingSection{}, ErrResponse
+	}
+	section, ok := ret[module]
+	if !ok {
+		return crossingSection{}, ErrResponse
+	}
+	return section, nil
+}
+
+// Write line-crossing/intrusion config. Rows of detection_region are replaced
+// with delete and add (like motion regions), if cam fail in the middle previous
+// rows are restored. Detection values are written after rows
+func (o *Tapo) setCrossing(component, getMethod, setMethod, module string, detection detectionInfo, rows []map[string]string) error {
+	old, err := o.getCrossing(component, getMethod, module)
+	if err != nil {
+		return err
+	}
+	if err := o.replaceCrossing(module, old.DetectionRegion, rows); err != nil {
+		current, errRead := o.getCrossing(component, getMethod, module)
+		if errRead != nil {
+			return errors.Join(err, errRead)
+		}
+		if errRestore := o.replaceCrossing(module, current.DetectionRegion, sortedRegions(old.DetectionRegion)); errRestore != nil {
+			return errors.Join(err, fmt.Errorf("gotapo: %s regions not restored: %w", module, errRestore))
+		}
+		return err
+	}
+	_, err = o.multiple(crossingSetTemplate(setMethod, module, crossingSection{Detection: detection}))
+	return err
+}
+
+// Remove rows "old" of detection_region and add "rows"
+func (o *Tapo) replaceCrossing(module string, old []map[string]map[string]string, rows []map[string]string) error {
+	names := []string{}
+	for _, v := range old {
+		for name := range v {
+			names = append(names, name)
+		}
+	}
+	if len(names) > 0 {
+		if _, err := o.multiple(crossingRemoveTemplate(module, names)); err != nil {
+			return err
+		}
+	}
+	for _, v := range rows {
+		if _, err := o.multiple(crossingAddTemplate(module, v)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Points of region from values like "pt1_x", "pt1_y"
+func regionPoints(values map[string]string) []Point {
+	pts := []Point{}
+	for i := 1; ; i++ {
+		x, okX := values["pt"+strconv.Itoa(i)+"_x"]
+		y, okY := values["pt"+strconv.Itoa(i)+"_y"]
+		if !okX || !okY {
+			break
+		}
+		pt := Point{}
+		pt.X, _ = strconv.Atoi(x)
+		pt.Y, _ = strconv.Atoi(y)
+		pts = append(pts, pt)
+	}
+	return pts
+}
+
+// Values of region like "pt1_x", "pt1_y" from points
+func regionValues(pts []Point) map[string]string {
+	values := map[string]string{}
+	for k, v := range pts {
+		values["pt"+strconv.Itoa(k+1)+"_x"] = strconv.Itoa(v.X)
+		values["pt"+strconv.Itoa(k+1)+"_y"] = strconv.Itoa(v.Y)
+	}
+	return values
+}
+
+// Regions of section ordered by name
+func sortedRegions(list []map[string]map[string]string) []map[string]string {
+	names := []string{}
+	all := map[string]map[string]string{}
+	for _, v := range list {
+		for name, values := range v {
+			names = append(names, name)
+			all[name] = values
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return regionNumber(names[i]) < regionNumber(names[j])
+	})
+	ret := []map[string]string{}
+	for _, name := range names {
+		ret = append(ret, all[name])
+	}
+	return ret
+}
+
+// LineCrossing read line-crossing detection config.
+// ErrUnsupported for models without line-crossing detection
+func (o *Tapo) LineCrossing() (LineCrossing, error) {
+	section, err := o.getCrossing("linecrossingDetection", "getLinecrossingDetectionConfig", "linecrossing_detection")
+	if err != nil {
+		return LineCrossing{}, err
+	}
+	c := LineCrossing{
+		Enabled:     sBool(section.Detection.Enabled),
+		Sensitivity: sensitivityFromString(section.Detection.Sensitivity),
+	}
+	for _, v := range sortedRegions(section.DetectionRegion) {
+		pts := regionPoints(v)
+		if len(pts) < 2 {
+			continue
+		}
+		c.Lines = append(c.Lines, Line{Start: pts[0], End: pts[1], Direction: LineDirection(v["direction"])})
+	}
+	return c, nil
+}
+
+// SetLineCrossing validate and write line-crossing detection config.
+// ErrUnsupported for models without line-crossing detection
+func (o *Tapo) SetLineCrossing(c LineCrossing) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	detection := detectionInfo{
+		Enabled:     new(Types).xBool(c.Enabled).Default,
+		Sensitivity: strconv.Itoa(c.Sensitivity),
+	}
+	rows := []map[string]string{}
+	for _, v := range c.Lines {
+		values := regionValues([]Point{v.Start, v.End})
+		values["direction"] = string(v.Direction)
+		rows = append(rows, values)
+	}
+	return o.setCrossing("linecrossingDetection", "getLinecrossingDetectionConfig", "setLinecrossingDetectionConfig",
+		"linecrossing_detection", detection, rows)
+}
+
+// Intrusion read area intrusion detection config.
+// ErrUnsupported for models without intrusion detection
+func (o *Tapo) Intrusion() (Intrusion, error) {
+	section, err := o.getCrossing("intrusionDetection", "getIntrusionDetectionConfig", "intrusion_detection")
+	if err != nil {
+		return Intrusion{}, err
+	}
+	c := Intrusion{
+		Enabled:     sBool(section.Detection.Enabled),
+		Sensitivity: sensitivityFromString(section.Detection.Sensitivity),
+	}
+	for _, v := range sortedRegions(section.DetectionRegion) {
+		c.Areas = append(c.Areas, Polygon{Points: regionPoints(v)})
+	}
+	return c, nil
+}
+
+// SetIntrusion validate and write area intrusion detection config.
+// ErrUnsupported for models without intrusion detection
+func (o *Tapo) SetIntrusion(c Intrusion) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	detection := detectionInfo{
+		Enabled:     new(Types).xBool(c.Enabled).Default,
+		Sensitivity: strconv.Itoa(c.Sensitivity),
+	}
+	rows := []map[string]string{}
+	for _, v := range c.Areas {
+		rows = append(rows, regionValues(v.Points))
+	}
+	return o.setCrossing("intrusionDetection", "getIntrusionDetectionConfig", "setIntrusionDetectionConfig",
+		"intrusion_detection", detection, rows)
+}
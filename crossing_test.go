@@ -0,0 +1,135 @@
+package gotapo_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/KusoKaihatsuSha/gotapo"
+)
+
+func TestLineCrossingValidate(t *testing.T) {
+	line := gotapo.Line{Start: gotapo.Point{X: 0, Y: 5000}, End: gotapo.Point{X: 10000, Y: 5000}, Direction: gotapo.LineBoth}
+	bad := map[string]gotapo.LineCrossing{
+		"too many lines": {Lines: []gotapo.Line{line, line, line, line, line}},
+		"bad direction":  {Lines: []gotapo.Line{{Start: line.Start, End: line.End, Direction: "up"}}},
+		"zero length":    {Lines: []gotapo.Line{{Start: line.Start, End: line.Start, Direction: gotapo.LineAToB}}},
+		"out of grid":    {Lines: []gotapo.Line{{Start: line.Start, End: gotapo.Point{X: 10001}, Direction: gotapo.LineAToB}}},
+		"sensitivity":    {Sensitivity: 101},
+	}
+	_, c := connect(t, true)
+	for name, v := range bad {
+		if err := c.SetLineCrossing(v); err == nil {
+			t.Errorf("%s accepted", name)
+		}
+	}
+}
+
+func TestIntrusionValidate(t *testing.T) {
+	square := gotapo.Polygon{Points: []gotapo.Point{{X: 0, Y: 0}, {X: 100, Y: 0}, {X: 100, Y: 100}, {X: 0, Y: 100}}}
+	many := gotapo.Polygon{}
+	for i := 0; i <= gotapo.MaxPolygonPoints; i++ {
+		many.Points = append(many.Points, gotapo.Point{X: i * 100, Y: i * i})
+	}
+	bad := map[string]gotapo.Intrusion{
+		"too many areas":  {Areas: []gotapo.Polygon{square, square, square, square, square}},
+		"too many points": {Areas: []gotapo.Polygon{many}},
+		"two points":      {Areas: []gotapo.Polygon{{Points: square.Points[:2]}}},
+		"zero area":       {Areas: []gotapo.Polygon{{Points: []gotapo.Point{{X: 0, Y: 0}, {X: 50, Y: 50}, {X: 100, Y: 100}}}}},
+	}
+	_, c := connect(t, true)
+	for name, v := range bad {
+		if err := c.SetIntrusion(v); err == nil {
+			t.Errorf("%s accepted", name)
+		}
+	}
+}
+
+func TestCrossingUnsupported(t *testing.T) {
+	s, c := connect(t, true)
+	s.RemoveComponents("linecrossingDetection", "intrusionDetection")
+	if _, err := c.LineCrossing(); !errors.Is(err, gotapo.ErrUnsupported) {
+		t.Fatalf("error %v, want unsupported", err)
+	}
+	if err := c.SetLineCrossing(gotapo.LineCrossing{}); !errors.Is(err, gotapo.ErrUnsupported) {
+		t.Fatalf("error %v, want unsupported", err)
+	}
+	if _, err := c.Intrusion(); !errors.Is(err, gotapo.ErrUnsupported) {
+		t.Fatalf("error %v, want unsupported", err)
+	}
+}
+
+func TestLineCrossingRoundTrip(t *testing.T) {
+	_, c := connect(t, true)
+	want := gotapo.LineCrossing{
+		Enabled:     true,
+		Sensitivity: 70,
+		Lines: []gotapo.Line{
+			{Start: gotapo.Point{X: 0, Y: 5000}, End: gotapo.Point{X: 10000, Y: 5000}, Direction: gotapo.LineAToB},
+			{Start: gotapo.Point{X: 5000, Y: 0}, End: gotapo.Point{X: 5000, Y: 10000}, Direction: gotapo.LineBoth},
+			{Start: gotapo.Point{X: 100, Y: 100}, End: gotapo.Point{X: 900, Y: 900}, Direction: gotapo.LineBToA},
+		},
+	}
+	if err := c.SetLineCrossing(want); err != nil {
+		t.Fatal(err)
+	}
+	// less lines must remove rows over new count
+	want.Lines = want.Lines[1:2]
+	if err := c.SetLineCrossing(want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.LineCrossing()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("line-crossing %+v, want %+v", got, want)
+	}
+}
+
+func TestIntrusionRoundTrip(t *testing.T) {
+	_, c := connect(t, true)
+	square := gotapo.Polygon{Points: []gotapo.Point{{X: 0, Y: 0}, {X: 100, Y: 0}, {X: 100, Y: 100}, {X: 0, Y: 100}}}
+	triangle := gotapo.Polygon{Points: []gotapo.Point{{X: 5000, Y: 5000}, {X: 9000, Y: 5000}, {X: 7000, Y: 9000}}}
+	want := gotapo.Intrusion{Enabled: true, Sensitivity: 30, Areas: []gotapo.Polygon{square, triangle}}
+	if err := c.SetIntrusion(want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.Intrusion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("intrusion %+v, want %+v", got, want)
+	}
+	want = gotapo.Intrusion{Sensitivity: 30}
+	if err := c.SetIntrusion(want); err != nil {
+		t.Fatal(err)
+	}
+	if got, err = c.Intrusion(); err != nil || len(got.Areas) != 0 || got.Enabled {
+		t.Fatalf("intrusion %+v (%v), want without areas", got, err)
+	}
+}
+
+func TestCrossingRestoreOnFailure(t *testing.T) {
+	s, c := connect(t, true)
+	old := gotapo.Intrusion{Enabled: true, Sensitivity: 50, Areas: []gotapo.Polygon{
+		{Points: []gotapo.Point{{X: 0, Y: 0}, {X: 100, Y: 0}, {X: 100, Y: 100}}},
+	}}
+	if err := c.SetIntrusion(old); err != nil {
+		t.Fatal(err)
+	}
+	s.TableLimit = 1
+	next := old
+	next.Areas = append(next.Areas, next.Areas[0])
+	if err := c.SetIntrusion(next); !errors.Is(err, gotapo.ErrInvalidParams) {
+		t.Fatalf("error %v, want invalid params", err)
+	}
+	got, err := c.Intrusion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, old) {
+		t.Fatalf("intrusion %+v after failure, want restored %+v", got, old)
+	}
+}
@@ -0,0 +1,224 @@
+package gotapo
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// detectors type of AI detections of cam
+type detectors struct {
+	Motion  *Detector
+	Person  *Detector
+	Vehicle *Detector
+	Pet     *Detector
+	BabyCry *Detector
+	Bark    *Detector
+	Meow    *Detector
+	Glass   *Detector
+	Tamper  *Detector
+}
+
+// Detector is uniform access to one detection of cam.
+// Enabled and Sensitivity (0-100) are filled by Get and sent by Set.
+type Detector struct {
+	Name        string
+	Enabled     bool
+	Sensitivity int
+	o           *Tapo
+	module      string
+	section     string
+	method      string
+	get         func(values ...any) any
+	levels      bool
+	digital     bool
+	mu          sync.Mutex
+}
+
+// type set detection config
+type detectionSet struct {
+	Method string                              `json:"method"`
+	Data   map[string]map[string]detectionInfo `json:"params"`
+}
+
+// type detection config values
+type detectionInfo struct {
+	Enabled            string `json:"enabled"`
+	Sensitivity        string `json:"sensitivity,omitempty"`
+	DigitalSensitivity string `json:"digital_sensitivity,omitempty"`
+}
+
+// type detection config return (inside multipleRequest)
+type detectionRet map[string]map[string]detectionInfo
+
+func detectionSetTemplate(values ...any) detectionSet {
+	t := detectionSet{}
+	t.Method = values[0].(string)
+	info := detectionInfo{Enabled: values[3].(string)}
+	if values[5].(bool) {
+		info.DigitalSensitivity = values[4].(string)
+	} else {
+		info.Sensitivity = values[4].(string)
+	}
+	t.Data = map[string]map[string]detectionInfo{
+		values[1].(string): {
+			values[2].(string): info,
+		},
+	}
+	return t
+}
+
+// Fill all detectors of cam
+func (o *Tapo) initDetectors() {
+	o.Detectors = new(detectors)
+	o.Detectors.Motion = o.newDetector("motion", "motion_detection", "motion_det", "DetectionConfig", false,
+		func(values ...any) any { return detectionConfigTemplate(values...) })
+	o.Detectors.Motion.digital = true
+	o.Detectors.Person = o.newDetector("person", "people_detection", "detection", "PersonDetectionConfig", false,
+		func(values ...any) any { return personDetectionConfigTemplate(values...) })
+	o.Detectors.Vehicle = o.newDetector("vehicle", "vehicle_detection", "detection", "VehicleDetectionConfig", false,
+		func(values ...any) any { return vehicleDetectionConfigTemplate(values...) })
+	o.Detectors.Pet = o.newDetector("pet", "pet_detection", "detection", "PetDetectionConfig", false,
+		func(values ...any) any { return petDetectionConfigTemplate(values...) })
+	o.Detectors.BabyCry = o.newDetector("baby cry", "sound_detection", "bcd", "BCDConfig", true,
+		func(values ...any) any { return bcdConfigTemplate(values...) })
+	o.Detectors.Bark = o.newDetector("bark", "bark_detection", "detection", "BarkDetectionConfig", false,
+		func(values ...any) any { return barkDetectionConfigTemplate(values...) })
+	o.Detectors.Meow = o.newDetector("meow", "meow_detection", "detection", "MeowDetectionConfig", false,
+		func(values ...any) any { return meowDetectionConfigTemplate(values...) })
+	o.Detectors.Glass = o.newDetector("glass break", "glass_detection", "detection", "GlassDetectionConfig", false,
+		func(values ...any) any { return glassDetectionConfigTemplate(values...) })
+	o.Detectors.Tamper = o.newDetector("tamper", "tamper_detection", "tamper_det", "TamperDetectionConfig", true,
+		func(values ...any) any { return tamperDetectionConfigTemplate(values...) })
+}
+
+func (o *Tapo) newDetector(name, module, section, method string, levels bool, get func(values ...any) any) *Detector {
+	return &Detector{
+		Name:    name,
+		o:       o,
+		module:  module,
+		section: section,
+		method:  method,
+		get:     get,
+		levels:  levels,
+	}
+}
+
+// Get read real state of detector from cam
+func (d *Detector) Get() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.read()
+}
+
+// Set write Enabled and Sensitivity to cam
+func (d *Detector) Set() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.write()
+}
+
+func (d *Detector) read() error {
+	result, err := d.o.multiple(d.get())
+	if err != nil {
+		return err
+	}
+	ret := detectionRet{}
+	if err := json.Unmarshal(result.Result.Responses[0].Result, &ret); err != nil {
+		return ErrResponse
+	}
+	info, ok := ret[d.module][d.section]
+	if !ok {
+		return ErrResponse
+	}
+	d.Enabled = sBool(info.Enabled)
+	d.Sensitivity = sensitivityFromString(info.Sensitivity)
+	if d.digital {
+		d.Sensitivity = sensitivityFromString(info.DigitalSensitivity)
+	}
+	return nil
+}
+
+func (d *Detector) write() error {
+	if d.Sensitivity < 0 || d.Sensitivity > 100 {
+		return fmt.Errorf("gotapo: %s sensitivity %d out of range 0-100", d.Name, d.Sensitivity)
+	}
+	sens := strconv.Itoa(d.Sensitivity)
+	if d.levels {
+		sens = sensitivityToLevel(d.Sensitivity)
+	}
+	_, err := d.o.multiple(
+		detectionSetTemplate(
+			"set"+d.method,
+			d.module,
+			d.section,
+			new(Types).xBool(d.Enabled).Default,
+			sens,
+			d.digital,
+		),
+	)
+	return err
+}
+
+// SetEnabled turn detector without change of sensitivity
+func (d *Detector) SetEnabled(value bool) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.read(); err != nil {
+		return err
+	}
+	d.Enabled = value
+	return d.write()
+}
+
+// SetSensitivity change sensitivity (0-100) without turn detector
+func (d *Detector) SetSensitivity(value int) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.read(); err != nil {
+		return err
+	}
+	d.Sensitivity = value
+	return d.write()
+}
+
+// On is turn detector
+func (d *Detector) On() {
+	if err := d.SetEnabled(true); err != nil {
+		p(err)
+	}
+}
+
+// Off is turn detector
+func (d *Detector) Off() {
+	if err := d.SetEnabled(false); err != nil {
+		p(err)
+	}
+}
+
+// Sensitivity of cam may be number or level
+func sensitivityFromString(value string) int {
+	switch value {
+	case "low":
+		return 20
+	case "normal", "medium":
+		return 50
+	case "high":
+		return 80
+	}
+	v, _ := strconv.Atoi(value)
+	return v
+}
+
+// Some detections get only levels
+func sensitivityToLevel(value int) string {
+	switch {
+	case value < 35:
+		return "low"
+	case value < 65:
+		return "normal"
+	default:
+		return "high"
+	}
+}
@@ -0,0 +1,47 @@
+package gotapo_test
+
+import (
+	"testing"
+)
+
+func TestDetectorSetEnabled(t *testing.T) {
+	s, c := connect(t, true)
+	if err := c.Detectors.Person.SetEnabled(true); err != nil {
+		t.Fatal(err)
+	}
+	if v := s.Value("people_detection", "detection", "enabled"); v != "on" {
+		t.Fatalf("person detection %v, want on", v)
+	}
+	if v := s.Value("people_detection", "detection", "sensitivity"); v != "50" {
+		t.Fatalf("sensitivity %v, want kept 50", v)
+	}
+}
+
+func TestDetectorLevels(t *testing.T) {
+	s, c := connect(t, true)
+	if err := c.Detectors.BabyCry.SetSensitivity(90); err != nil {
+		t.Fatal(err)
+	}
+	if v := s.Value("sound_detection", "bcd", "sensitivity"); v != "high" {
+		t.Fatalf("sensitivity %v, want high", v)
+	}
+	if v := s.Value("sound_detection", "bcd", "enabled"); v != "off" {
+		t.Fatalf("baby cry detection %v, want kept off", v)
+	}
+	if err := c.Detectors.BabyCry.Get(); err != nil {
+		t.Fatal(err)
+	}
+	if c.Detectors.BabyCry.Sensitivity != 80 {
+		t.Fatalf("sensitivity %d, want 80", c.Detectors.BabyCry.Sensitivity)
+	}
+}
+
+func TestDetectorRange(t *testing.T) {
+	s, c := connect(t, true)
+	if err := c.Detectors.Vehicle.SetSensitivity(101); err == nil {
+		t.Fatal("sensitivity 101 accepted")
+	}
+	if v := s.Value("vehicle_detection", "detection", "sensitivity"); v != "50" {
+		t.Fatalf("sensitivity %v, want kept 50", v)
+	}
+}
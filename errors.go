@@ -0,0 +1,100 @@
+package gotapo
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrResponse is returned when response of cam can't be decoded
+var ErrResponse = errors.New("gotapo: check! response struct outdated")
+
+// ErrUnsupported is returned when function not supported by model of cam
+var ErrUnsupported = errors.New("gotapo: not supported by cam")
+
+// Error is known error_code of cam. Use with errors.Is like
+// errors.Is(err, ErrPrivacyOn)
+type Error struct {
+	Code    int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("gotapo: %s (error code %d)", e.Message, e.Code)
+}
+
+// Is match ErrUnsupported for codes about not supported method
+func (e *Error) Is(target error) bool {
+	return target == ErrUnsupported && (e == ErrMethodNotSupported || e == ErrMethodNotExist)
+}
+
+// Catalog of known error codes of cam
+var (
+	ErrSessionExpired     = &Error{-40401, "session expired or wrong credentials"}
+	ErrNonceRequired      = &Error{-40413, "secure login need nonce"}
+	ErrLocked             = &Error{-40404, "login temporary locked after wrong attempts"}
+	ErrInvalidCredentials = &Error{-40209, "invalid login credentials"}
+	ErrMethodNotSupported = &Error{-40210, "method not supported"}
+	ErrMethodNotExist     = &Error{-40105, "method does not exist"}
+	ErrInvalidParams      = &Error{-40106, "parameter to get/do does not exist"}
+	ErrInvalidSetParams   = &Error{-40101, "parameter to set does not exist"}
+	ErrPresetNotFound     = &Error{-64302, "preset not found"}
+	ErrPresetDeleted      = &Error{-64321, "preset was deleted"}
+	ErrPatrolMode         = &Error{-64303, "action not allowed in patrol mode"}
+	ErrRangeReached       = &Error{-64304, "max pan/tilt range reached"}
+	ErrPrivacyOn          = &Error{-64324, "action not allowed in privacy mode"}
+	ErrUserNotAuthorized  = &Error{-71103, "user not authorized"}
+)
+
+var catalog = map[int]*Error{}
+
+func init() {
+	for _, v := range []*Error{
+		ErrSessionExpired,
+		ErrNonceRequired,
+		ErrLocked,
+		ErrInvalidCredentials,
+		ErrMethodNotSupported,
+		ErrMethodNotExist,
+		ErrInvalidParams,
+		ErrInvalidSetParams,
+		ErrPresetNotFound,
+		ErrPresetDeleted,
+		ErrPatrolMode,
+		ErrRangeReached,
+		ErrPrivacyOn,
+		ErrUserNotAuthorized,
+	} {
+		catalog[v.Code] = v
+	}
+}
+
+// ErrorByCode is error of catalog for error_code of cam, nil if code unknown
+func ErrorByCode(code int) *Error {
+	return catalog[code]
+}
+
+// MethodError is error_code of cam in answer on method
+type MethodError struct {
+	Method string
+	Code   int
+}
+
+func (e *MethodError) Error() string {
+	if known := ErrorByCode(e.Code); known != nil {
+		return fmt.Sprintf("gotapo: %s failed: %s (error code %d)", e.Method, known.Message, e.Code)
+	}
+	return fmt.Sprintf("gotapo: %s failed with error code %d", e.Method, e.Code)
+}
+
+// Unwrap is error of catalog (for errors.Is)
+func (e *MethodError) Unwrap() error {
+	if known := ErrorByCode(e.Code); known != nil {
+		return known
+	}
+	return nil
+}
+
+// errCode make error from error_code of cam
+func errCode(method string, code int) error {
+	return &MethodError{Method: method, Code: code}
+}
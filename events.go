@@ -0,0 +1,265 @@
+package gotapo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// EventType is type of detection event
+type EventType string
+
+const (
+	// EventMotion is motion detected
+	EventMotion EventType = "motion"
+
+	// EventPerson is person detected
+	EventPerson EventType = "person"
+
+	// EventVehicle is vehicle detected
+	EventVehicle EventType = "vehicle"
+
+	// EventPet is pet detected
+	EventPet EventType = "pet"
+
+	// EventCry is baby cry detected
+	EventCry EventType = "cry"
+
+	// EventTamper is tamper of cam detected
+	EventTamper EventType = "tamper"
+
+	// EventUnknown is event with unknown code (see Event.Code)
+	EventUnknown EventType = "unknown"
+)
+
+// Known codes of alarm_type in detection list of cam
+var eventCodes = map[int]EventType{
+	2:  EventMotion,
+	6:  EventPerson,
+	7:  EventCry,
+	8:  EventTamper,
+	9:  EventVehicle,
+	10: EventPet,
+}
+
+// Known names of last_alarm_type of cam
+var eventNames = map[string]EventType{
+	"motion":  EventMotion,
+	"people":  EventPerson,
+	"person":  EventPerson,
+	"vehicle": EventVehicle,
+	"pet":     EventPet,
+	"bcd":     EventCry,
+	"cry":     EventCry,
+	"tamper":  EventTamper,
+}
+
+// Event is detection on cam
+type Event struct {
+	Type  EventType
+	Code  int
+	Start time.Time
+	End   time.Time
+}
+
+// type search events
+type detectionList struct {
+	Method string `json:"method"`
+	Data   struct {
+		Type struct {
+			SearchDetectionList struct {
+				StartIndex int   `json:"start_index"`
+				Channel    int   `json:"channel"`
+				StartTime  int64 `json:"start_time"`
+				EndTime    int64 `json:"end_time"`
+				EndIndex   int   `json:"end_index"`
+			} `json:"search_detection_list"`
+		} `json:"playback"`
+	} `json:"params"`
+}
+
+// type search events return (inside multipleRequest)
+type detectionListRet struct {
+	Playback struct {
+		SearchDetectionList []struct {
+			StartTime json.Number `json:"start_time"`
+			EndTime   json.Number `json:"end_time"`
+			AlarmType json.Number `json:"alarm_type"`
+		} `json:"search_detection_list"`
+	} `json:"playback"`
+}
+
+// type last alarm
+type lastAlarm struct {
+	Method string `json:"method"`
+	Data   struct {
+		Type struct {
+			Name []string `json:"name"`
+		} `json:"system"`
+	} `json:"params"`
+}
+
+// type last alarm return (inside multipleRequest)
+type lastAlarmRet struct {
+	System struct {
+		LastAlarmInfo struct {
+			LastAlarmType string      `json:"last_alarm_type"`
+			LastAlarmTime json.Number `json:"last_alarm_time"`
+		} `json:"last_alarm_info"`
+	} `json:"system"`
+}
+
+func detectionListTemplate(values ...any) detectionList {
+	t := detectionList{}
+	t.Method = "searchDetectionList"
+	t.Data.Type.SearchDetectionList.StartIndex = 0
+	t.Data.Type.SearchDetectionList.Channel = 0
+	t.Data.Type.SearchDetectionList.StartTime = values[0].(int64)
+	t.Data.Type.SearchDetectionList.EndTime = values[1].(int64)
+	t.Data.Type.SearchDetectionList.EndIndex = 999
+	return t
+}
+
+func lastAlarmTemplate(values ...any) lastAlarm {
+	t := lastAlarm{}
+	t.Method = "getLastAlarmInfo"
+	t.Data.Type.Name = []string{"last_alarm_info"}
+	return t
+}
+
+func unix(n json.Number) time.Time {
+	v, err := n.Int64()
+	if err != nil || v <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(v, 0)
+}
+
+// Read events from detection list (needs SD card)
+func (o *Tapo) searchEvents(from, to time.Time) ([]Event, error) {
+	result, err := o.multiple(detectionListTemplate(from.Unix(), to.Unix()))
+	if err != nil {
+		return nil, err
+	}
+	ret := new(detectionListRet)
+	if err := json.Unmarshal(result.Result.Responses[0].Result, ret); err != nil {
+		return nil, ErrResponse
+	}
+	events := []Event{}
+	for _, v := range ret.Playback.SearchDetectionList {
+		code, _ := strconv.Atoi(v.AlarmType.String())
+		e := Event{Type: EventUnknown, Code: code, Start: unix(v.StartTime), End: unix(v.EndTime)}
+		if t, ok := eventCodes[code]; ok {
+			e.Type = t
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+// Read last alarm of cam
+func (o *Tapo) lastEvent() ([]Event, error) {
+	result, err := o.multiple(lastAlarmTemplate())
+	if err != nil {
+		return nil, err
+	}
+	ret := new(lastAlarmRet)
+	if err := json.Unmarshal(result.Result.Responses[0].Result, ret); err != nil {
+		return nil, ErrResponse
+	}
+	info := ret.System.LastAlarmInfo
+	e := Event{Type: EventUnknown, Start: unix(info.LastAlarmTime)}
+	if e.Start.IsZero() {
+		return []Event{}, nil
+	}
+	e.End = e.Start
+	if t, ok := eventNames[info.LastAlarmType]; ok {
+		e.Type = t
+	}
+	return []Event{e}, nil
+}
+
+// Events poll cam every EventsInterval and send new detections to channel.
+// Events are read from detection list, if it fail (no SD card) from last alarm.
+// Event in progress (zero End) is sent again with End when it is finished.
+// Errors of polling go to Settings.EventsError (from goroutine of polling),
+// same error is reported once until success. Channel is closed after cancel of ctx
+func (o *Tapo) Events(ctx context.Context) <-chan Event {
+	ch := make(chan Event)
+	interval := o.Settings.EventsInterval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	report := o.Settings.EventsError
+	if report == nil {
+		report = func(err error) { p(err) }
+	}
+	go func() {
+		defer close(ch)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		since := time.Now().Truncate(time.Second)
+		seen := map[string]Event{}
+		lastErr := ""
+		for {
+			// events in progress are searched until they are finished
+			from := since
+			for _, e := range seen {
+				if e.End.IsZero() && e.Start.Before(from) {
+					from = e.Start
+				}
+			}
+			events, err := o.searchEvents(from.Add(-interval), time.Now().Add(interval))
+			if err != nil {
+				var errLast error
+				if events, errLast = o.lastEvent(); errLast != nil {
+					err = errors.Join(err, errLast)
+				}
+			}
+			switch {
+			case err == nil:
+				lastErr = ""
+			case err.Error() != lastErr:
+				lastErr = err.Error()
+				report(err)
+			}
+			sort.Slice(events, func(i, j int) bool {
+				return events[i].Start.Before(events[j].Start)
+			})
+			for _, e := range events {
+				key := fmt.Sprintf("%s/%d/%d", e.Type, e.Code, e.Start.Unix())
+				if prev, ok := seen[key]; ok && (!prev.End.IsZero() || e.End.IsZero()) {
+					continue
+				} else if !ok && e.Start.Before(since) {
+					continue
+				}
+				seen[key] = e
+				select {
+				case ch <- e:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if len(events) > 0 && events[len(events)-1].Start.After(since) {
+				since = events[len(events)-1].Start
+			}
+			// older events are skipped by since, events in progress are
+			// forgotten after hour
+			for key, e := range seen {
+				if e.Start.Before(since) && (!e.End.IsZero() || e.Start.Before(since.Add(-time.Hour))) {
+					delete(seen, key)
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return ch
+}
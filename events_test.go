@@ -0,0 +1,96 @@
+package gotapo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/KusoKaihatsuSha/gotapo"
+	"github.com/KusoKaihatsuSha/gotapo/gotapotest"
+)
+
+func events(t *testing.T, c *gotapo.Tapo) (<-chan gotapo.Event, <-chan error) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	errs := make(chan error, 10)
+	c.Settings.EventsInterval = 20 * time.Millisecond
+	c.Settings.EventsError = func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	}
+	return c.Events(ctx), errs
+}
+
+func next(t *testing.T, ch <-chan gotapo.Event) gotapo.Event {
+	t.Helper()
+	select {
+	case e := <-ch:
+		return e
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event")
+	}
+	return gotapo.Event{}
+}
+
+func TestEventsInProgress(t *testing.T) {
+	s, c := connect(t, true)
+	ch, _ := events(t, c)
+	start := time.Now().Truncate(time.Second)
+	s.Detect(gotapotest.Detection{Code: 6, Start: start})
+	e := next(t, ch)
+	if e.Type != gotapo.EventPerson || !e.Start.Equal(start) || !e.End.IsZero() {
+		t.Fatalf("event %+v, want person in progress", e)
+	}
+	end := start.Add(3 * time.Second)
+	s.Detect(gotapotest.Detection{Code: 6, Start: start, End: end})
+	e = next(t, ch)
+	if e.Type != gotapo.EventPerson || !e.Start.Equal(start) || !e.End.Equal(end) {
+		t.Fatalf("event %+v, want finished person", e)
+	}
+	select {
+	case e := <-ch:
+		t.Fatalf("event %+v sent again", e)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestEventsRawCode(t *testing.T) {
+	s, c := connect(t, true)
+	ch, _ := events(t, c)
+	start := time.Now().Truncate(time.Second)
+	s.Detect(gotapotest.Detection{Code: 21, Start: start, End: start})
+	s.Detect(gotapotest.Detection{Code: 22, Start: start, End: start})
+	codes := map[int]bool{}
+	for i := 0; i < 2; i++ {
+		e := next(t, ch)
+		if e.Type != gotapo.EventUnknown {
+			t.Fatalf("event %+v, want unknown", e)
+		}
+		codes[e.Code] = true
+	}
+	if !codes[21] || !codes[22] {
+		t.Fatalf("codes %v, want 21 and 22", codes)
+	}
+}
+
+func TestEventsListRetry(t *testing.T) {
+	s, c := connect(t, true)
+	s.Fail(gotapotest.FaultSearch, 1)
+	ch, errs := events(t, c)
+	select {
+	case err := <-errs:
+		if err == nil {
+			t.Fatal("nil error")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("error of search not reported")
+	}
+	start := time.Now().Truncate(time.Second)
+	s.Detect(gotapotest.Detection{Code: 2, Start: start, End: start})
+	if e := next(t, ch); e.Type != gotapo.EventMotion || e.Code != 2 {
+		t.Fatalf("event %+v, want motion from detection list", e)
+	}
+}
@@ -5,21 +5,23 @@ package gotapo
 
 import (
 	"bytes"
+	"context"
 	"crypto/aes"
 	"crypto/cipher"
-	"crypto/md5"
 	"crypto/sha256"
-	"crypto/tls"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -43,6 +45,9 @@ const (
 	MethodLogin = "login"
 
 	EncryptType = "3"
+
+	// PrivacyPresetName is name of preset with saved position before privacy mode
+	PrivacyPresetName = "gotapo_privacy"
 )
 
 var (
@@ -110,17 +115,24 @@ type settings struct {
 	VisibleOsdTime             *child
 	VisibleOsdText             *child
 	OsdText                    string
-	DetectSensitivity          int
+	DetectSensitivity          int // 1, 2, 3 - low, normal, high (raw 0-100 - Detectors.Motion)
 	DetectSoundAlternativeMode *child
 	DetectEnableSound          *child
 	DetectEnableFlash          *child
 	Move                       *child
+	PrivacyPark                *child
+	PrivacyParkPreset          string
+	PrivacyParkX               int
+	PrivacyParkY               int
+	EventsInterval             time.Duration
+	EventsError                func(err error) // errors of Events polling (nil - printed)
 }
 
 // child assignment of function
 type child struct {
 	Value bool
 	run   func()
+	lock  sync.Locker
 }
 
 // Tapo is general type with Vals
@@ -128,13 +140,12 @@ type Tapo struct {
 	Parameters           map[string]string
 	Host                 string
 	Port                 string
-	UserDef              string
 	User                 string
-	Password             string
 	UserID               string
 	Rotate               bool
 	FishEye              bool
-	Flip                 bool
+	Flip                 bool // Orientation.Rotate180 (with mirror it is read back as other mirror, Flip false)
+	Orientation          Orientation
 	stokID               string
 	TimeStr              string
 	userGroup            string
@@ -147,16 +158,36 @@ type Tapo struct {
 	deviceID             string
 	presets              []*presets
 	lastPosition         int
+	parkedPreset         string
+	components           map[string]int
 	LastFile             string
 	Elements             *elements
 	Settings             *settings
+	Detectors            *detectors
 	NextPreset           func()
 	Reboot               func()
-	InsecureAuth         bool
-	Iv                   []byte
-	Key                  []byte
+	iv                   []byte
+	key                  []byte
 	Seq                  string
 	Encrypt              bool
+	transport            http.RoundTripper
+	client               *http.Client
+	trace                func(t Trace)
+	traceSeq             int
+	interceptors         []Interceptor
+	rate                 time.Duration
+	retry                Retry
+	lockedUntil          time.Time
+	loginErr             *LoginError
+	authMode             AuthMode
+	credentials          CredentialProvider
+	authUser             string
+	authInfo             AuthInfo
+	secure               bool
+	queue                *queue
+	mu                   sync.Mutex
+	config               sync.Mutex
+	state                sync.Mutex
 }
 
 // Action is general Action cam
@@ -193,37 +224,6 @@ type device struct {
 	} `json:"device_info"`
 }
 
-// deviceRet type for device cam return
-type deviceRet struct {
-	Result struct {
-		Responses []struct {
-			Method string `json:"method"`
-			Result struct {
-				DeviceInfo struct {
-					BasicInfo struct {
-						Ffs         bool   `json:"ffs"`
-						DeviceType  string `json:"device_type"`
-						DeviceModel string `json:"device_model"`
-						DeviceName  string `json:"device_name"`
-						DeviceInfo  string `json:"device_info"`
-						HwVersion   string `json:"hw_version"`
-						SwVersion   string `json:"sw_version"`
-						DeviceAlias string `json:"device_alias"`
-						Features    string `json:"features"`
-						Barcode     string `json:"barcode"`
-						Mac         string `json:"mac"`
-						DevID       string `json:"dev_id"`
-						OemID       string `json:"oem_id"`
-						HwDesc      string `json:"hw_desc"`
-					} `json:"basic_info"`
-				} `json:"device_info"`
-			} `json:"result"`
-			ErrorCode int `json:"error_code"`
-		} `json:"responses"`
-	} `json:"result"`
-	ErrorCode int `json:"error_code"`
-}
-
 type moveTo struct {
 	Method string `json:"method"`
 	Motor  struct {
@@ -284,6 +284,27 @@ type nextPreset struct {
 	} `json:"preset"`
 }
 
+// savePreset type for saving current position as preset
+type savePreset struct {
+	Method string `json:"method"`
+	Preset struct {
+		SetPreset struct {
+			Name    string `json:"name"`
+			SavePtz string `json:"save_ptz"`
+		} `json:"set_preset"`
+	} `json:"preset"`
+}
+
+// removePreset type for removing preset
+type removePreset struct {
+	Method string `json:"method"`
+	Preset struct {
+		RemovePreset struct {
+			ID []string `json:"id"`
+		} `json:"remove_preset"`
+	} `json:"preset"`
+}
+
 // reboot type for rebooting
 type reboot struct {
 	Method string `json:"method"`
@@ -297,10 +318,14 @@ type alarm struct {
 	Method   string `json:"method"`
 	MsgAlarm struct {
 		Chn1MsgAlarmInfo struct {
-			AlarmType string   `json:"alarm_type"`
-			Enabled   string   `json:"enabled"`
-			LightType string   `json:"light_type"`
-			AlarmMode []string `json:"alarm_mode"`
+			AlarmType         string   `json:"alarm_type,omitempty"`
+			Enabled           string   `json:"enabled"`
+			LightType         string   `json:"light_type,omitempty"`
+			AlarmMode         []string `json:"alarm_mode"`
+			SoundAlarmEnabled string   `json:"sound_alarm_enabled"`
+			LightAlarmEnabled string   `json:"light_alarm_enabled"`
+			AlarmVolume       string   `json:"alarm_volume,omitempty"`
+			AlarmDuration     string   `json:"alarm_duration,omitempty"`
 		} `json:"chn1_msg_alarm_info"`
 	} `json:"msg_alarm"`
 }
@@ -469,61 +494,6 @@ type getTimeRet struct {
 	ErrorCode int `json:"error_code"`
 }
 
-// type working with OSD return
-type getOSDRet struct {
-	OSD struct {
-		Date struct {
-			Name    string `json:".name"`
-			Type    string `json:".type"`
-			XCoor   string `json:"x_coor"`
-			YCoor   string `json:"y_coor"`
-			Enabled string `json:"enabled"`
-		} `json:"date"`
-		Week struct {
-			Name    string `json:".name"`
-			Type    string `json:".type"`
-			Enabled string `json:"enabled"`
-			XCoor   string `json:"x_coor"`
-			YCoor   string `json:"y_coor"`
-		} `json:"week"`
-		Font struct {
-			Name      string `json:".name"`
-			Type      string `json:".type"`
-			Display   string `json:"display"`
-			Size      string `json:"size"`
-			ColorType string `json:"color_type"`
-			Color     string `json:"color"`
-		} `json:"font"`
-		LabelInfo []struct {
-			LabelInfo1 struct {
-				Name    string `json:".name"`
-				Type    string `json:".type"`
-				XCoor   string `json:"x_coor"`
-				YCoor   string `json:"y_coor"`
-				Enabled string `json:"enabled"`
-				Text    string `json:"text"`
-			} `json:"label_info_1,omitempty"`
-			LabelInfo2 struct {
-				Name    string `json:".name"`
-				Type    string `json:".type"`
-				Enabled string `json:"enabled"`
-				Text    string `json:"text"`
-				XCoor   string `json:"x_coor"`
-				YCoor   string `json:"y_coor"`
-			} `json:"label_info_2,omitempty"`
-			LabelInfo3 struct {
-				Name    string `json:".name"`
-				Type    string `json:".type"`
-				Enabled string `json:"enabled"`
-				Text    string `json:"text"`
-				XCoor   string `json:"x_coor"`
-				YCoor   string `json:"y_coor"`
-			} `json:"label_info_3,omitempty"`
-		} `json:"label_info"`
-	} `json:"OSD"`
-	ErrorCode int `json:"error_code"`
-}
-
 // type working with OSD
 type getOSD struct {
 	Method string `json:"method"`
@@ -542,15 +512,6 @@ type setLed struct {
 	} `json:"led"`
 }
 
-type setPersonDetect struct {
-	Method string `json:"method"`
-	Data   struct {
-		Type struct {
-			Value string `json:"enabled"`
-		} `json:"detection"`
-	} `json:"people_detection"`
-}
-
 type deviceInfo struct {
 	Method string `json:"method"`
 	Data   struct {
@@ -676,30 +637,6 @@ type lastAlarmInfo struct {
 	} `json:"params"`
 }
 
-type lastAlarmInfoResponse struct {
-	Result struct {
-		Responses []struct {
-			Method string `json:"method"`
-			Result struct {
-				MsgAlarm struct {
-					Chn1MsgAlarmInfo struct {
-						Name              string   `json:".name"`
-						Type              string   `json:".type"`
-						SoundAlarmEnabled string   `json:"sound_alarm_enabled"`
-						LightAlarmEnabled string   `json:"light_alarm_enabled"`
-						AlarmType         string   `json:"alarm_type"`
-						LightType         string   `json:"light_type"`
-						AlarmMode         []string `json:"alarm_mode"`
-						Enabled           string   `json:"enabled"`
-					} `json:"chn1_msg_alarm_info"`
-				} `json:"msg_alarm"`
-			} `json:"result"`
-			ErrorCode int `json:"error_code"`
-		} `json:"responses"`
-	} `json:"result"`
-	ErrorCode int `json:"error_code"`
-}
-
 type ledStatus struct {
 	Method string `json:"method"`
 	Data   struct {
@@ -759,6 +696,7 @@ type alarmPlan struct {
 	Method string `json:"method"`
 	Data   struct {
 		Type struct {
+			Name []string `json:"name"`
 		} `json:"msg_alarm_plan"`
 	} `json:"params"`
 }
@@ -881,34 +819,6 @@ type firmwareAutoUpgradeConfig struct {
 }
 
 // type working with OSD
-type osd struct {
-	Method string `json:"method"`
-	OSD    struct {
-		Date struct {
-			Enabled string `json:"enabled"`
-			XCoor   int    `json:"x_coor"`
-			YCoor   int    `json:"y_coor"`
-		} `json:"date"`
-		Week struct {
-			Enabled string `json:"enabled"`
-			XCoor   int    `json:"x_coor"`
-			YCoor   int    `json:"y_coor"`
-		} `json:"week"`
-		Font struct {
-			Color     string `json:"color"`
-			ColorType string `json:"color_type"`
-			Display   string `json:"display"`
-			Size      string `json:"size"`
-		} `json:"font"`
-		LabelInfo1 struct {
-			Enabled string `json:"enabled"`
-			Text    string `json:"text"`
-			XCoor   int    `json:"x_coor"`
-			YCoor   int    `json:"y_coor"`
-		} `json:"label_info_1"`
-	} `json:"OSD"`
-}
-
 type queryResponse struct {
 	ErrorCode int `json:"error_code"`
 	Seq       int `json:"seq"`
@@ -917,6 +827,23 @@ type queryResponse struct {
 	} `json:"result"`
 }
 
+// type multiple request return with raw results
+type manyRet struct {
+	Result struct {
+		Responses []struct {
+			Method    string          `json:"method"`
+			Result    json.RawMessage `json:"result"`
+			ErrorCode int             `json:"error_code"`
+		} `json:"responses"`
+	} `json:"result"`
+	ErrorCode int `json:"error_code"`
+}
+
+// type single request return
+type errorRet struct {
+	ErrorCode int `json:"error_code"`
+}
+
 type many struct {
 	Method string `json:"method"`
 	Params struct {
@@ -974,34 +901,17 @@ func manyTemplate(values ...any) many {
 	return t
 }
 
-func osdTemplate(values ...any) osd {
-	t := osd{}
-	t.Method = MethodSet
-	t.OSD.Date.Enabled = values[0].(string)
-	t.OSD.Date.XCoor = 0
-	t.OSD.Date.YCoor = 0
-	t.OSD.Font.Color = "white"
-	t.OSD.Font.ColorType = "auto"
-	t.OSD.Font.Display = "ntnb"
-	t.OSD.Font.Size = "auto"
-	t.OSD.LabelInfo1.Enabled = values[1].(string)
-	t.OSD.LabelInfo1.Text = values[2].(string)
-	t.OSD.LabelInfo1.XCoor = 0
-	t.OSD.LabelInfo1.YCoor = 450
-	//---china weeks---
-	t.OSD.Week.Enabled = new(Types).xBool(false).Default
-	t.OSD.Week.XCoor = 0
-	t.OSD.Week.YCoor = 0
-	return t
-}
-
 func alarmTemplate(values ...any) alarm {
 	t := alarm{}
 	t.Method = MethodSet
 	t.MsgAlarm.Chn1MsgAlarmInfo.AlarmType = values[0].(string)
-	t.MsgAlarm.Chn1MsgAlarmInfo.LightType = "1"
 	t.MsgAlarm.Chn1MsgAlarmInfo.AlarmMode = values[1].([]string)
 	t.MsgAlarm.Chn1MsgAlarmInfo.Enabled = values[2].(string)
+	t.MsgAlarm.Chn1MsgAlarmInfo.LightType = values[3].(string)
+	t.MsgAlarm.Chn1MsgAlarmInfo.AlarmVolume = values[4].(string)
+	t.MsgAlarm.Chn1MsgAlarmInfo.AlarmDuration = values[5].(string)
+	t.MsgAlarm.Chn1MsgAlarmInfo.SoundAlarmEnabled = values[6].(string)
+	t.MsgAlarm.Chn1MsgAlarmInfo.LightAlarmEnabled = values[7].(string)
 	return t
 }
 
@@ -1012,6 +922,21 @@ func nextPresetTemplate(values ...any) nextPreset {
 	return t
 }
 
+func savePresetTemplate(values ...any) savePreset {
+	t := savePreset{}
+	t.Method = MethodDo
+	t.Preset.SetPreset.Name = values[0].(string)
+	t.Preset.SetPreset.SavePtz = "1"
+	return t
+}
+
+func removePresetTemplate(values ...any) removePreset {
+	t := removePreset{}
+	t.Method = MethodDo
+	t.Preset.RemovePreset.ID = []string{values[0].(string)}
+	return t
+}
+
 func loginNewTemplate(values ...any) loginInsecure {
 	t := loginInsecure{}
 	t.Method = MethodLogin
@@ -1054,16 +979,7 @@ func setImageFlipTemplate(values ...any) setImageFlip {
 func detectTemplate(values ...any) detect {
 	t := detect{}
 	t.Method = MethodSet
-	switch values[1].(int) {
-	case 1:
-		t.MotionDetection.MotionDet.DigitalSensitivity = "20"
-	case 2:
-		t.MotionDetection.MotionDet.DigitalSensitivity = "50"
-	case 3:
-		t.MotionDetection.MotionDet.DigitalSensitivity = "80"
-	default:
-		t.MotionDetection.MotionDet.DigitalSensitivity = "20"
-	}
+	t.MotionDetection.MotionDet.DigitalSensitivity = values[1].(string)
 	t.MotionDetection.MotionDet.Enabled = values[0].(string)
 	return t
 }
@@ -1134,13 +1050,6 @@ func setLedTemplate(values ...any) setLed {
 	return t
 }
 
-func setPersonDetectTemplate(values ...any) setPersonDetect {
-	t := setPersonDetect{}
-	t.Method = MethodSet
-	t.Data.Type.Value = values[0].(string)
-	return t
-}
-
 func deviceInfoTemplate(values ...any) deviceInfo {
 	t := deviceInfo{}
 	t.Method = "getDeviceInfo"
@@ -1274,6 +1183,13 @@ func connectionTypeTemplate(values ...any) connectionType {
 	return t
 }
 
+func alarmPlanTemplate(values ...any) alarmPlan {
+	t := alarmPlan{}
+	t.Method = "getAlarmPlan"
+	t.Data.Type.Name = []string{"chn1_msg_alarm_plan"}
+	return t
+}
+
 func lightFrequencyInfoTemplate(values ...any) lightFrequencyInfo {
 	t := lightFrequencyInfo{}
 	t.Method = "getLightFrequencyInfo"
@@ -1358,14 +1274,24 @@ func firmwareAutoUpgradeConfigTemplate(values ...any) firmwareAutoUpgradeConfig
 	return t
 }
 
-// Connect is general function for connecting to Camera
-func Connect(host string, user string, password string) *Tapo {
+// Connect is general function for connecting to Camera.
+// Host may be with port like "192.168.1.10:8443" (default 443)
+func Connect(host string, user string, password string, options ...Option) *Tapo {
 	o := new(Tapo)
 	o.LastFile, _ = os.Getwd()
 	o.Host = host
 	o.Port = "443"
+	if h, port, err := net.SplitHostPort(host); err == nil {
+		o.Host = h
+		o.Port = port
+	}
 	o.User = user
-	o.Password = password
+	secret := []byte(password)
+	o.setPassword(secret)
+	zero(secret)
+	for _, option := range options {
+		option(o)
+	}
 	o.init()
 	o.auth()
 	o.getDevice()
@@ -1377,14 +1303,17 @@ func Connect(host string, user string, password string) *Tapo {
 
 // Firsty initialise
 func (o *Tapo) init() {
-	o.hashedPasswordMD5 = hashNHexOld(o.Password)
-	o.hashedPasswordSha256 = hashNHex(o.Password)
-	o.hostURL = `https://` + o.Host + `:` + o.Port
+	o.hostURL = `https://` + net.JoinHostPort(o.Host, o.Port)
+	if o.transport == nil {
+		o.transport = defaultTransport()
+	}
+	o.client = &http.Client{Transport: o.transport}
+	o.queue = newQueue(o.rate)
 
 	newParam := make(map[string]string)
 	o.Parameters = newParam
 	o.Parameters["Host"] = o.Host
-	o.Parameters["Referer"] = "https://" + o.Host + ":" + o.Port
+	o.Parameters["Referer"] = o.hostURL
 	o.Parameters["Accept"] = "application/json"
 	o.Parameters["Accept-Encoding"] = "gzip, deflate"
 	o.Parameters["User-Agent"] = "Tapo CameraClient Android"
@@ -1405,6 +1334,16 @@ func (o *Tapo) init() {
 
 	o.Settings.OsdText = ""
 
+	o.Settings.PrivacyPark = new(child)
+	o.Settings.PrivacyPark.Value = false
+	o.Settings.PrivacyPark.run = fnil
+
+	o.Settings.PrivacyParkPreset = ""
+	o.Settings.PrivacyParkX = 0
+	o.Settings.PrivacyParkY = -180
+
+	o.Settings.EventsInterval = 10 * time.Second
+
 	o.Elements.PrivacyMode = new(child)
 	o.Elements.PrivacyMode.Value = false
 	o.Elements.PrivacyMode.run = o.setPrivacy
@@ -1437,6 +1376,8 @@ func (o *Tapo) init() {
 	o.Elements.DetectMode.Value = false
 	o.Elements.DetectMode.run = o.setDetect
 
+	o.initDetectors()
+
 	o.Elements.DetectPersonMode = new(child)
 	o.Elements.DetectPersonMode.Value = false
 	o.Elements.DetectPersonMode.run = o.setDetectPerson
@@ -1457,7 +1398,7 @@ func (o *Tapo) init() {
 
 	o.Elements.AlarmMode = new(child)
 	o.Elements.AlarmMode.Value = false
-	o.Elements.AlarmMode.run = o.setAlarm
+	o.Elements.AlarmMode.run = o.setAlarmMode
 
 	o.Elements.AlarmModeUpdateFlash = new(child)
 	o.Elements.AlarmModeUpdateFlash.Value = false
@@ -1491,6 +1432,7 @@ func (o *Tapo) init() {
 
 	o.NextPreset = o.setNextPreset
 	o.Reboot = o.rebootDevice
+	o.lockChildren()
 }
 
 // Pack and encode request
@@ -1509,56 +1451,158 @@ func pack(request any, key, iv []byte) secure {
 
 // POST query to cam
 func (o *Tapo) query(data any, host string, encrypt bool) []byte {
+	b, _ := o.send(data, host, encrypt)
+	return b
+}
+
+// POST query to cam with error (through interceptors)
+func (o *Tapo) send(data any, host string, encrypt bool) ([]byte, error) {
+	request, _ := json.Marshal(data)
+	c := &Call{
+		Method:  requestMethod(request),
+		Request: data,
+		URL:     host,
+		Encrypt: encrypt,
+		Header:  http.Header{},
+	}
+	return chain(o.interceptors, o.post)(c)
+}
+
+// POST call to cam
+func (o *Tapo) post(c *Call) (b []byte, err error) {
+	data, host, encrypt := c.Request, c.URL, c.Encrypt
+	if o.trace != nil {
+		start := time.Now()
+		request, _ := json.Marshal(data)
+		defer func() {
+			o.emit(host, encrypt, request, b, err, time.Since(start))
+		}()
+	}
+	w := &wire{}
 	if encrypt {
-		data = pack(data, o.Key, o.Iv)
+		w.plain, _ = json.Marshal(data)
+		w.key = append([]byte{}, o.key...)
+		w.iv = append([]byte{}, o.iv...)
+		data = pack(data, o.key, o.iv)
+	}
+	dataBody, err := json.Marshal(data)
+	if err != nil {
+		return []byte{}, err
 	}
-	dataBody, _ := json.Marshal(data)
 	body := bytes.NewReader(dataBody)
-	req, _ := http.NewRequest("POST", host, body)
+	req, err := http.NewRequest("POST", host, body)
+	if err != nil {
+		return []byte{}, err
+	}
+	req = req.WithContext(context.WithValue(req.Context(), wireKey{}, w))
 	for k, v := range o.Parameters {
 		req.Header.Add(k, v)
 	}
+	for k, v := range c.Header {
+		req.Header[k] = v
+	}
 	if encrypt {
 		req.Header.Add("Seq", o.Seq)
 		req.Header.Add("Tapo_tag", hashNHex(hashNHex(o.hashedPassword)+string(dataBody)+o.Seq))
 	}
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: true,
-		},
-	}
-	client := &http.Client{Transport: tr}
-	resp, err := client.Do(req)
+	resp, err := o.client.Do(req)
 	if err != nil {
-		return []byte{}
+		return []byte{}, err
 	}
-	b, err := io.ReadAll(resp.Body)
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return []byte{}, &StatusError{StatusCode: resp.StatusCode}
+	}
+	b, err = io.ReadAll(resp.Body)
 	if err != nil {
-		return []byte{}
+		return []byte{}, err
 	}
 	if encrypt {
 		result := new(queryResponse)
-		json.Unmarshal(b, &result)
-		return []byte(decodeAES([]byte(decodeB64(result.Result.Response)), o.Key, o.Iv))
+		if err := json.Unmarshal(b, &result); err != nil {
+			return b, ErrResponse
+		}
+		if result.ErrorCode != 0 {
+			return b, errCode("securePassthrough", result.ErrorCode)
+		}
+		return []byte(decodeAES([]byte(decodeB64(result.Result.Response)), o.key, o.iv)), nil
 	}
-	defer resp.Body.Close()
-	return b
+	return b, nil
+}
+
+// Send requests in one multipleRequest and check error codes
+func (o *Tapo) multiple(requests ...any) (*manyRet, error) {
+	ret, err := o.request(manyTemplate(requests...))
+	if err != nil {
+		return nil, err
+	}
+	result := new(manyRet)
+	if err := json.NewDecoder(bytes.NewReader(ret)).Decode(&result); err != nil {
+		return nil, ErrResponse
+	}
+	if result.ErrorCode != 0 {
+		return result, errCode(MethodMR, result.ErrorCode)
+	}
+	if len(result.Result.Responses) != len(requests) {
+		return result, ErrResponse
+	}
+	for _, v := range result.Result.Responses {
+		if v.ErrorCode != 0 {
+			return result, errCode(v.Method, v.ErrorCode)
+		}
+	}
+	return result, nil
+}
+
+// Send one request and check error code
+func (o *Tapo) apply(request any) error {
+	ret, err := o.request(request)
+	if err != nil {
+		return err
+	}
+	result := new(errorRet)
+	if err := json.NewDecoder(bytes.NewReader(ret)).Decode(&result); err != nil {
+		return ErrResponse
+	}
+	if result.ErrorCode != 0 {
+		return errCode(methodOf(request), result.ErrorCode)
+	}
+	return nil
+}
+
+// Method name of template
+func methodOf(request any) string {
+	if v := reflect.Indirect(reflect.ValueOf(request)); v.Kind() == reflect.Struct {
+		if m := v.FieldByName("Method"); m.IsValid() && m.Kind() == reflect.String {
+			return m.String()
+		}
+	}
+	return ""
 }
 
 // Check insecure of authorise.
-// At this moment the simplest way is send hash(sha256)
-// (but not best and stable).
+// Cam with secure login answer nonce on login without password
+// (so probe not counted as wrong attempt).
 // On firmware >= 1.3.9(11 for new hardware) old type of authorise with hash(md5) will not valid
 func (o *Tapo) auth() {
-	o.hashedPassword = o.hashedPasswordSha256
 	o.Encrypt = false
-	result := new(updateStokReturn)
-	json.Unmarshal(o.query(updateStokTemplate(o.User, o.hashedPassword), o.hostURL, false), &result)
-	if result.ErrorCode == 0 && result.Result.StartSeq != nil {
-		o.InsecureAuth = true
-	} else {
-		o.InsecureAuth = false
+	if err := o.fetchCredentials(); err != nil {
+		p(err)
+		return
+	}
+	switch o.authMode {
+	case AuthLegacy:
+		o.secure = false
+		return
+	case AuthSecure:
+		o.secure = true
+		return
+	case AuthAdmin:
+		o.authUser = adminUser
 	}
+	result := new(loginInsecureResponse)
+	json.Unmarshal(o.query(loginInitTemplate(o.login()), o.hostURL, false), &result)
+	o.secure = result.Result.Data.Nonce != ""
 }
 
 func hash(value string) []byte {
@@ -1570,10 +1614,6 @@ func hashNHex(value string) string {
 	return strings.ToUpper(fmt.Sprintf("%x", sha256.Sum256([]byte(value))))
 }
 
-func hashNHexOld(value string) string {
-	return strings.ToUpper(fmt.Sprintf("%x", md5.Sum([]byte(value))))
-}
-
 func encodeAES(text []byte, key []byte, iv []byte) string {
 	pad := aes.BlockSize - len(text)%aes.BlockSize
 	bText := append(text, bytes.Repeat([]byte{byte(pad)}, pad)...)
@@ -1592,7 +1632,16 @@ func decodeAES(text []byte, key []byte, iv []byte) string {
 	if err != nil {
 		p(err)
 	}
+	if len(decoded)%aes.BlockSize != 0 {
+		return ""
+	}
 	cipher.NewCBCDecrypter(block, iv).CryptBlocks(decoded, decoded)
+	// cut padding
+	if l := len(decoded); l > 0 {
+		if pad := int(decoded[l-1]); pad > 0 && pad <= aes.BlockSize && pad <= l {
+			decoded = decoded[:l-pad]
+		}
+	}
 	return string(decoded)
 }
 
@@ -1608,74 +1657,93 @@ func decodeB64(text string) string {
 	return string(data)
 }
 
-func (o *Tapo) getDigestPasswd() (string, string) {
-	result := new(loginInsecureResponse)
-	err := json.Unmarshal(o.query(loginInitTemplate(o.User), o.hostURL, false), &result)
+func (o *Tapo) getDigestPasswd() (string, string, error) {
+	b, err := o.send(loginInitTemplate(o.login()), o.hostURL, false)
 	if err != nil {
-		p("Check! response struct outdated")
+		return "", "", err
 	}
-	return hashNHex(o.hashedPassword + result.Result.Data.Nonce), result.Result.Data.Nonce
+	result := new(loginInsecureResponse)
+	if err := json.Unmarshal(b, &result); err != nil {
+		return "", "", ErrResponse
+	}
+	if result.Result.Data.Nonce == "" {
+		return "", "", o.locked(loginError(b))
+	}
+	return hashNHex(o.hashedPassword + result.Result.Data.Nonce), result.Result.Data.Nonce, nil
 }
 
 // Refresh stok. For authentication
-func (o *Tapo) update() {
-	if o.InsecureAuth {
-		o.updateInsecure()
-	} else {
-		o.updateRaw()
+func (o *Tapo) update() error {
+	if err := o.fetchCredentials(); err != nil {
+		return o.logged(err)
+	}
+	if err := o.lockout(); err != nil {
+		return err
+	}
+	if o.secure {
+		return o.logged(o.updateInsecure())
 	}
+	return o.logged(o.updateRaw())
 }
 
-func (o *Tapo) updateInsecure() {
+func (o *Tapo) updateInsecure() error {
 	o.hashedPassword = o.hashedPasswordSha256
-	hashPass, nonce := o.getDigestPasswd()
-	o.Key = hash("lsk" + nonce + hashPass)[:aes.BlockSize]
-	o.Iv = hash("ivb" + nonce + hashPass)[:aes.BlockSize]
+	hashPass, nonce, err := o.getDigestPasswd()
+	if err != nil {
+		return err
+	}
+	zero(o.key)
+	zero(o.iv)
+	o.key = hash("lsk" + nonce + hashPass)[:aes.BlockSize]
+	o.iv = hash("ivb" + nonce + hashPass)[:aes.BlockSize]
 	o.Encrypt = true
+	b, err := o.send(loginNewTemplate(o.login(), hashPass+nonce), o.hostURL, false)
+	if err != nil {
+		return err
+	}
 	result := new(updateStokReturn)
-	json.Unmarshal(o.query(loginNewTemplate(o.User, hashPass+nonce), o.hostURL, false), &result)
+	json.Unmarshal(b, &result)
 	if result.ErrorCode == 0 && result.Result.StartSeq != nil {
 		//version >= 1.3.9(11)
 		o.stokID = result.Result.Stok
 		o.hostURLStok = o.hostURL + `/stok=` + o.stokID + `/ds`
 		o.Seq = strconv.Itoa(*result.Result.StartSeq)
 		o.userGroup = result.Result.UserGroup
-	} else {
-		p(`Authenticate failed. Try use another cred.`)
+		return nil
 	}
+	p(`Authenticate failed. Try use another cred.`)
+	return o.locked(loginError(b))
 }
 
-func (o *Tapo) updateRaw() {
+func (o *Tapo) updateRaw() error {
 	o.hashedPassword = o.hashedPasswordMD5
 	o.Encrypt = false
+	b, err := o.send(updateStokTemplate(o.login(), o.hashedPassword), o.hostURL, false)
+	if err != nil {
+		return err
+	}
 	result := new(updateStokReturn)
-	json.Unmarshal(o.query(updateStokTemplate(o.User, o.hashedPassword), o.hostURL, false), &result)
+	json.Unmarshal(b, &result)
 	if result.ErrorCode == 0 && result.Result.StartSeq == nil {
 		//version < 1.3.9(11)
 		o.stokID = result.Result.Stok
 		o.hostURLStok = o.hostURL + `/stok=` + o.stokID + `/ds`
 		o.userGroup = result.Result.UserGroup
-	} else {
-		p(`Authenticate failed. Try use another cred. login - "admin", password - your password in Tapo account.`)
-		if o.UserDef != o.User {
-			o.UserDef = o.User
-			o.User = "admin"
-			p(`App will be trying with "admin" with next operation(if your pass on rtsp equal pass your account). If next operation exist.`)
-		} else {
-			p(`Authenticate failed. Login by "admin" has failed.`)
-			panic("!end operation authenticate!")
-		}
+		return nil
 	}
+	p(`Authenticate failed. Try use another cred. login - "admin", password - your password in Tapo account.`)
+	return o.locked(loginError(b))
 }
 
 // Get information about device tapo c200
 func (o *Tapo) getDevice() {
-	o.update()
-	ret := o.query(manyTemplate(deviceInfoTemplate("")), o.hostURLStok, o.Encrypt)
-	result := new(deviceRet)
-	json.NewDecoder(bytes.NewReader(ret)).Decode(&result)
-	o.deviceID = result.Result.Responses[0].Result.DeviceInfo.BasicInfo.DevID
-	o.deviceModel = result.Result.Responses[0].Result.DeviceInfo.BasicInfo.DeviceModel
+	info, err := o.Info()
+	if err != nil {
+		p(err)
+		return
+	}
+	o.deviceID = info.ID
+	o.deviceModel = info.Model
 }
 
 // Manual move
@@ -1684,8 +1752,7 @@ func (o *Tapo) getDevice() {
 //
 // -10 = 10 degree reverse
 func (o *Tapo) setMovePosition(x, y int) {
-	o.update()
-	o.query(movePositionTemplate(x, y), o.hostURLStok, o.Encrypt)
+	o.request(movePositionTemplate(x, y))
 }
 
 // Move action by X and Y
@@ -1697,44 +1764,82 @@ func (o *Tapo) setMoveAction() {
 
 // Get all making Presets in App
 func (o *Tapo) getPresets() {
-	o.update()
-	ret := o.query(manyTemplate(presetConfigTemplate("")), o.hostURLStok, o.Encrypt)
+	o.loadPresets()
+}
+
+// Read presets with error
+func (o *Tapo) loadPresets() error {
+	ret, err := o.request(manyTemplate(presetConfigTemplate("")))
+	if err != nil {
+		return err
+	}
 	result := new(presetListReturn)
-	json.NewDecoder(bytes.NewReader(ret)).Decode(&result)
+	if err := json.NewDecoder(bytes.NewReader(ret)).Decode(&result); err != nil {
+		return ErrResponse
+	}
+	if result.ErrorCode != 0 {
+		return errCode(MethodMR, result.ErrorCode)
+	}
 	if len(result.Result.Responses) > 0 {
 		o.Rotate = true
 	}
+	o.presets = nil
+	o.parkedPreset = ""
 	for _, v := range result.Result.Responses {
+		if v.ErrorCode != 0 {
+			return errCode(v.Method, v.ErrorCode)
+		}
 		for kk, vv := range v.Result.Preset.Preset.ID {
+			if v.Result.Preset.Preset.Name[kk] == PrivacyPresetName {
+				o.parkedPreset = vv
+				continue
+			}
 			o.presets = append(o.presets, &presets{ID: vv, Name: v.Result.Preset.Preset.Name[kk]})
 		}
 	}
+	return nil
 }
 
 // Switch to next preset
 func (o *Tapo) setNextPreset() {
-	if o.Rotate {
-		o.update()
-		o.lastPosition = o.rLast()
-		if len(o.presets) > o.lastPosition+1 {
-			o.lastPosition++
-		} else {
-			o.lastPosition = 0
-		}
-		next := o.presets[o.lastPosition]
-		if o.Settings.PresetChangeOsd.Value {
+	if err := o.GotoNextPreset(); err != nil {
+		p(err)
+	}
+}
+
+// GotoNextPreset switch to next preset (Rotate must be on). With PresetChangeOsd
+// name of preset is written to OSD label 1. Name longer than OSDTextLimit is error:
+// cam is moved, but text of OSD is not changed (not truncated)
+func (o *Tapo) GotoNextPreset() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if !o.Rotate || len(o.presets) == 0 {
+		return nil
+	}
+	o.lastPosition = o.rLast()
+	if len(o.presets) > o.lastPosition+1 {
+		o.lastPosition++
+	} else {
+		o.lastPosition = 0
+	}
+	next := o.presets[o.lastPosition]
+	var errOSD error
+	if o.Settings.PresetChangeOsd.Value {
+		if errOSD = checkOSDText(next.Name); errOSD == nil {
 			o.Settings.OsdText = next.Name
 			o.Settings.VisibleOsdText.Value = true
 			o.Settings.VisibleOsdTime.Value = true
-			o.setOsdText()
+			errOSD = o.osdText(true, next.Name)
+		}
+		if errOSD != nil {
+			errOSD = fmt.Errorf("gotapo: OSD of preset %q: %w", next.Name, errOSD)
 		}
-		o.query(
-			nextPresetTemplate(next.ID),
-			o.hostURLStok,
-			o.Encrypt,
-		)
-		o.wLast(o.lastPosition)
 	}
+	if err := o.apply(nextPresetTemplate(next.ID)); err != nil {
+		return errors.Join(err, errOSD)
+	}
+	o.wLast(o.lastPosition)
+	return errOSD
 }
 
 // Write log last file
@@ -1757,7 +1862,10 @@ func (o *Tapo) rLast() int {
 func (o *Tapo) runAllPresets(timer string) {
 	if o.Rotate {
 		durDef, _ := time.ParseDuration(timer)
-		for range o.presets {
+		o.mu.Lock()
+		count := len(o.presets)
+		o.mu.Unlock()
+		for i := 0; i < count; i++ {
 			time.Sleep(durDef)
 			o.setNextPreset()
 		}
@@ -1766,8 +1874,7 @@ func (o *Tapo) runAllPresets(timer string) {
 
 // Reboot device
 func (o *Tapo) rebootDevice() {
-	o.update()
-	o.query(rebootTemplate(), o.hostURLStok, o.Encrypt)
+	o.request(rebootTemplate())
 }
 
 // special function xBool
@@ -1881,114 +1988,52 @@ func sBool(value interface{}) bool {
 	return false
 }
 
-func (o *Tapo) getAlarm() (string, []string, string) {
-	o.update()
-	result := new(lastAlarmInfoResponse)
-	json.NewDecoder(bytes.NewReader(o.query(manyTemplate(lastAlarmInfoTemplate("")), o.hostURLStok, o.Encrypt))).Decode(&result)
-	return result.Result.Responses[0].Result.MsgAlarm.Chn1MsgAlarmInfo.Enabled, result.Result.Responses[0].Result.MsgAlarm.Chn1MsgAlarmInfo.AlarmMode, result.Result.Responses[0].Result.MsgAlarm.Chn1MsgAlarmInfo.AlarmType
-}
-
-func (o *Tapo) updateAlarmSound() {
-	o.update()
-	enabled, list, alarmType := o.getAlarm()
-	newList := []string{}
-	for _, v := range list {
-		if v != "sound" && enabled == "on" {
-			newList = append(newList, v)
+// Turn alarm on detection.
+// DetectEnableSound - include noise
+// DetectSoundAlternativeMode - sound like a bip (without it type of sound on cam is kept)
+// DetectEnableFlash - blinking led diode
+// Without sound and flash only turn alarm, modes of cam are kept
+func (o *Tapo) setAlarmMode() {
+	err := o.UpdateAlarm(func(c *AlarmConfig) {
+		c.Enabled = o.Elements.AlarmMode.Value
+		if o.Settings.DetectEnableSound.Value || o.Settings.DetectEnableFlash.Value {
+			c.Sound = o.Settings.DetectEnableSound.Value
+			c.Light = o.Settings.DetectEnableFlash.Value
 		}
-	}
-
-	if o.Elements.AlarmModeUpdateSound.Value {
-		newList = append(newList, "sound")
-		enabled = "on"
-	}
-
-	if len(newList) == 0 {
-		enabled = "off"
-		newList = []string{"sound", "light"}
-	}
-
-	o.query(
-		alarmTemplate(
-			alarmType,
-			newList,
-			enabled,
-		),
-		o.hostURLStok,
-		o.Encrypt,
-	)
-}
-
-func (o *Tapo) updateAlarmFlash() {
-	o.update()
-	enabled, list, alarmType := o.getAlarm()
-	newList := []string{}
-	for _, v := range list {
-		if v != "light" && enabled == "on" {
-			newList = append(newList, v)
+		if o.Settings.DetectSoundAlternativeMode.Value {
+			c.AlarmType = AlarmTypeTone
 		}
+	})
+	if err != nil {
+		p(err)
 	}
-
-	if o.Elements.AlarmModeUpdateFlash.Value {
-		newList = append(newList, "light")
-		enabled = "on"
-	}
-
-	if len(newList) == 0 {
-		enabled = "off"
-		newList = []string{"sound", "light"}
-	}
-
-	o.query(
-		alarmTemplate(
-			alarmType,
-			newList,
-			enabled,
-		),
-		o.hostURLStok,
-		o.Encrypt,
-	)
 }
 
-// Set alarm mode
-// DetectEnableSound - include noise
-// DetectSoundAlternativeMode - sound like a bip
-// DetectEnableFlash - blinking led diode
-func (o *Tapo) setAlarm() {
-	o.update()
-
-	list := []string{}
-
-	if o.Settings.DetectEnableSound.Value && o.Settings.DetectEnableFlash.Value {
-		list = []string{"sound", "light"}
-	} else if !o.Settings.DetectEnableSound.Value && !o.Settings.DetectEnableFlash.Value {
-		list = []string{"sound", "light"}
-	} else if o.Settings.DetectEnableSound.Value {
-		list = []string{"sound"}
-	} else if o.Settings.DetectEnableFlash.Value {
-		list = []string{"light"}
+// Turn sound of alarm. Alarm is turned off without sound and light
+func (o *Tapo) updateAlarmSound() {
+	err := o.UpdateAlarm(func(c *AlarmConfig) {
+		c.Sound = o.Elements.AlarmModeUpdateSound.Value
+		c.Enabled = c.Sound || (c.Enabled && c.Light)
+	})
+	if err != nil {
+		p(err)
 	}
+}
 
-	alarmType := "0"
-	if o.Settings.DetectSoundAlternativeMode.Value {
-		alarmType = "1"
+// Turn light of alarm. Alarm is turned off without sound and light
+func (o *Tapo) updateAlarmFlash() {
+	err := o.UpdateAlarm(func(c *AlarmConfig) {
+		c.Light = o.Elements.AlarmModeUpdateFlash.Value
+		c.Enabled = c.Light || (c.Enabled && c.Sound)
+	})
+	if err != nil {
+		p(err)
 	}
-
-	o.query(
-		alarmTemplate(
-			alarmType,
-			list,
-			new(Types).xBool(o.Elements.AlarmMode.Value).Default,
-		),
-		o.hostURLStok,
-		o.Encrypt,
-	)
 }
 
 // Turn Indicator diode (red, green)
 func (o *Tapo) setLedAction(value string) { //on off
-	o.update()
-	o.query(setLedTemplate(value), o.hostURLStok, o.Encrypt)
+	o.request(setLedTemplate(value))
 }
 
 // Turn Indicator diode (red, green)
@@ -1998,147 +2043,253 @@ func (o *Tapo) setLed() {
 
 // Get Time
 func (o *Tapo) getTime() {
-	o.update()
 	result := new(getTimeRet)
-	json.Unmarshal(o.query(getTimeTemplate(), o.hostURLStok, o.Encrypt), &result)
+	ret, _ := o.request(getTimeTemplate())
+	json.Unmarshal(ret, &result)
 	o.TimeStr = result.System.ClockStatus.LocalTime
 }
 
 // Get Settings Image
 func (o *Tapo) getImageSettings() {
-	o.update()
 	result := new(getImageSettingsRet)
-	json.NewDecoder(bytes.NewReader(o.query(manyTemplate(ldcTemplate()), o.hostURLStok, o.Encrypt))).Decode(&result)
+	ret, err := o.request(manyTemplate(ldcTemplate()))
+	if err != nil {
+		p(err)
+		return
+	}
+	json.NewDecoder(bytes.NewReader(ret)).Decode(&result)
+	if len(result.Result.Responses) == 0 {
+		p(ErrResponse)
+		return
+	}
 	o.FishEye = new(Types).xBool(result.Result.Responses[0].Result.Image.Switch.Ldc).isTrue
-	o.Flip = result.Result.Responses[0].Result.Image.Switch.FlipType == "center"
+	image := result.Result.Responses[0].Result.Image
+	o.Orientation = orientationOf(image.Switch.FlipType, image.Switch.RotateType)
+	o.Flip = o.Orientation.Rotate180
 }
 
 // Set Correction
 func (o *Tapo) setImageCorrection() {
-	o.update()
-	o.query(setImageCorrectionTemplate(new(Types).xBool(o.Elements.ImageCorrection.Value).Default), o.hostURLStok, o.Encrypt)
+	o.request(setImageCorrectionTemplate(new(Types).xBool(o.Elements.ImageCorrection.Value).Default))
 }
 
-// Set Flip
+// Set Flip (rotate 180 degree, mirror is kept). With mirror cam keep it as
+// other mirror, so after next read Flip is false, but image is same
 func (o *Tapo) setImageFlip() {
-	val := new(Types).xBool(o.Elements.ImageFlip.Value).Default
-	if o.Elements.ImageFlip.Value {
-		val = "center"
+	v := o.Orientation
+	v.Rotate180 = o.Elements.ImageFlip.Value
+	if err := o.apply(setImageFlipTemplate(v.flip())); err != nil {
+		p(err)
+		return
 	}
-	o.update()
-	o.query(setImageFlipTemplate(val), o.hostURLStok, o.Encrypt)
+	o.Orientation = v
+	o.Flip = v.Rotate180
 }
 
 // Motion detect with sensitivity
 func (o *Tapo) getDetect() string {
-	o.update()
 	result := new(detectionConfigResponse)
-	ret := o.query(manyTemplate(detectionConfigTemplate("")), o.hostURLStok, o.Encrypt)
+	ret, _ := o.request(manyTemplate(detectionConfigTemplate("")))
 	json.NewDecoder(bytes.NewReader(ret)).Decode(&result)
+	if len(result.Result.Responses) == 0 {
+		return new(Types).xBool(false).Default
+	}
 	return result.Result.Responses[0].Result.MotionDetection.MotionDet.Enabled
 }
 
 // Motion detect with sensitivity
 func (o *Tapo) updateSens() {
-	o.update()
 	enabled := o.getDetect()
-	o.query(detectTemplate(enabled, o.Settings.DetectSensitivity), o.hostURLStok, o.Encrypt)
+	o.request(detectTemplate(enabled, digitalSensitivity(o.Settings.DetectSensitivity)))
 }
 
 // Motion detect with sensitivity
 func (o *Tapo) setDetect() {
-	o.update()
-	o.query(detectTemplate(new(Types).xBool(o.Elements.DetectMode.Value).Default, o.Settings.DetectSensitivity), o.hostURLStok, o.Encrypt)
+	o.request(detectTemplate(new(Types).xBool(o.Elements.DetectMode.Value).Default, digitalSensitivity(o.Settings.DetectSensitivity)))
 }
 
-// Motion detect with sensitivity
+// DetectSensitivity to digital sensitivity of cam
+//
+//	1, 2, 3 - low, normal, high (20, 50, 80)
+//	other - low
+func digitalSensitivity(value int) string {
+	switch value {
+	case 2:
+		return "50"
+	case 3:
+		return "80"
+	}
+	return "20"
+}
+
+// Person detect
 func (o *Tapo) setDetectPerson() {
-	o.update()
-	o.query(setPersonDetectTemplate(new(Types).xBool(o.Elements.DetectPersonMode.Value).Default), o.hostURLStok, o.Encrypt)
+	if err := o.Detectors.Person.SetEnabled(o.Elements.DetectPersonMode.Value); err != nil {
+		p(err)
+	}
 }
 
-// Turn camera in private mode with stop video channel
+// Turn camera in private mode with stop video channel.
+// With PrivacyPark lens will be turned away before and returned back after
 func (o *Tapo) setPrivacy() {
-	o.update()
-	o.query(privacyTemplate(new(Types).xBool(o.Elements.PrivacyMode.Value).Default), o.hostURLStok, o.Encrypt)
+	if err := o.privacy(o.Elements.PrivacyMode.Value); err != nil {
+		p(err)
+	}
+}
+
+// Turn camera in private mode with error
+func (o *Tapo) privacy(on bool) error {
+	mask := privacyTemplate(new(Types).xBool(on).Default)
+	if !o.Settings.PrivacyPark.Value || !o.Rotate {
+		return o.apply(mask)
+	}
+	if on {
+		masked, err := o.lensMasked()
+		if err != nil {
+			return err
+		}
+		// lens already parked, saved position is kept
+		if !masked {
+			if err := o.parkLens(); err != nil {
+				return err
+			}
+		}
+		return o.apply(mask)
+	}
+	// move back before mask off, so parked scene is not shown.
+	// Cam may refuse move in privacy mode, then mask off first
+	err := o.unparkLens()
+	if errors.Is(err, ErrPrivacyOn) {
+		if err := o.apply(mask); err != nil {
+			return err
+		}
+		return o.unparkLens()
+	}
+	if err != nil {
+		return err
+	}
+	return o.apply(mask)
+}
+
+// State of privacy mode on cam
+func (o *Tapo) lensMasked() (bool, error) {
+	result, err := o.multiple(lensMaskConfigTemplate())
+	if err != nil {
+		return false, err
+	}
+	ret := new(privacy)
+	if err := json.Unmarshal(result.Result.Responses[0].Result, ret); err != nil {
+		return false, ErrResponse
+	}
+	return sBool(ret.LensMask.LensMaskInfo.Enabled), nil
+}
+
+// Save current position in preset and move lens to park position.
+// Preset of previous park is overwritten by current position.
+// PrivacyParkPreset - id of preset for parking
+// PrivacyParkX, PrivacyParkY - move in degree if preset is empty (default fully down)
+func (o *Tapo) parkLens() error {
+	if o.parkedPreset != "" {
+		if err := o.apply(removePresetTemplate(o.parkedPreset)); err != nil {
+			return err
+		}
+	}
+	if err := o.apply(savePresetTemplate(PrivacyPresetName)); err != nil {
+		return err
+	}
+	if err := o.loadPresets(); err != nil {
+		return err
+	}
+	if o.parkedPreset == "" {
+		return errors.New("gotapo: position before privacy not saved, lens not parked")
+	}
+	if o.Settings.PrivacyParkPreset != "" {
+		return o.apply(nextPresetTemplate(o.Settings.PrivacyParkPreset))
+	}
+	return o.apply(movePositionTemplate(o.Settings.PrivacyParkX, o.Settings.PrivacyParkY))
+}
+
+// Return lens to saved position and remove temporary preset
+func (o *Tapo) unparkLens() error {
+	if o.parkedPreset == "" {
+		return nil
+	}
+	if err := o.apply(nextPresetTemplate(o.parkedPreset)); err != nil {
+		return err
+	}
+	if err := o.apply(removePresetTemplate(o.parkedPreset)); err != nil {
+		return err
+	}
+	return o.loadPresets()
 }
 
 // Turn irc flashlight
 func (o *Tapo) setNightMode() {
-	o.update()
-	o.query(nightModeTemplate(new(Types).xBool(o.Elements.NightMode.Value).Default), o.hostURLStok, o.Encrypt)
+	o.request(nightModeTemplate(new(Types).xBool(o.Elements.NightMode.Value).Default))
 }
 
 // Turn irc flashlight
 func (o *Tapo) setNightModeAuto() {
 	if o.Elements.NightModeAuto.Value {
-		o.update()
-		o.query(nightModeTemplate("auto"), o.hostURLStok, o.Encrypt)
+		o.request(nightModeTemplate("auto"))
 	}
 }
 
 // Autotracking all motion. BETA
 func (o *Tapo) setAutotracking() {
-	o.update()
-	o.query(autotrackingTemplate(new(Types).xBool(o.Elements.AutotrackingMode.Value).Default), o.hostURLStok, o.Encrypt)
-}
-
-// get Text OSD
-func (o *Tapo) getOsd() (string, string) {
-	o.update()
-	result := new(getOSDRet)
-	json.NewDecoder(bytes.NewReader(o.query(getOSDTemplate(), o.hostURLStok, o.Encrypt))).Decode(&result)
-	if len(o.Settings.OsdText) == 0 {
-		o.Settings.OsdText = result.OSD.LabelInfo[0].LabelInfo1.Text
-	}
-	return result.OSD.LabelInfo[0].LabelInfo1.Enabled, result.OSD.Date.Enabled
+	o.request(autotrackingTemplate(new(Types).xBool(o.Elements.AutotrackingMode.Value).Default))
 }
 
 // Text OSD
 func (o *Tapo) setOsdTime() {
-	textEnabled, _ := o.getOsd()
-	o.update()
-	o.query(
-		osdTemplate(
-			new(Types).xBool(o.Settings.VisibleOsdTime.Value).Default,
-			textEnabled,
-			o.Settings.OsdText,
-		),
-		o.hostURLStok,
-		o.Encrypt,
-	)
+	if err := o.UpdateOSD(func(v *OSD) {
+		v.Date.Enabled = o.Settings.VisibleOsdTime.Value
+	}); err != nil {
+		p(err)
+	}
 }
 
 // Text OSD
 func (o *Tapo) setOsdText() {
-	_, timeEnabled := o.getOsd()
-
-	if len(o.Settings.OsdText) > 16 {
-		//16 symbols, not bytes
-		o.Settings.OsdText = string([]rune(o.Settings.OsdText)[0:16])
+	if err := o.osdText(o.Settings.VisibleOsdText.Value, o.Settings.OsdText); err != nil {
+		p(err)
 	}
+}
 
-	o.update()
-	o.query(
-		osdTemplate(
-			timeEnabled,
-			new(Types).xBool(o.Settings.VisibleOsdText.Value).Default,
-			o.Settings.OsdText,
-		),
-		o.hostURLStok,
-		o.Encrypt,
-	)
+// Write label 1 of OSD and keep other values of cam (positions, font).
+// Empty text - text of cam
+func (o *Tapo) osdText(enabled bool, text string) error {
+	if err := checkOSDText(text); err != nil {
+		return err
+	}
+	return o.UpdateOSD(func(v *OSD) {
+		if len(v.Labels) == 0 {
+			v.Labels = append(v.Labels, OSDLabel{OSDItem: OSDItem{Y: 450}})
+		}
+		v.Labels[0].Enabled = enabled
+		if text != "" {
+			v.Labels[0].Text = text
+		}
+	})
 }
 
 // On is turn settings
 func (o *child) On() {
-	o.Value = true
-	o.run()
+	o.set(true)
 }
 
 // Off is turn settings
 func (o *child) Off() {
-	o.Value = false
+	o.set(false)
+}
+
+// Set value and run (one by one with other settings of cam)
+func (o *child) set(value bool) {
+	if o.lock != nil {
+		o.lock.Lock()
+		defer o.lock.Unlock()
+	}
+	o.Value = value
 	o.run()
 }
 
@@ -0,0 +1,98 @@
+package gotapo_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/KusoKaihatsuSha/gotapo"
+	"github.com/KusoKaihatsuSha/gotapo/gotapotest"
+)
+
+// Fake cam and client connected to it
+func connect(t *testing.T, secure bool, options ...gotapo.Option) (*gotapotest.Server, *gotapo.Tapo) {
+	t.Helper()
+	s := gotapotest.NewServer("cam", "secret", secure)
+	t.Cleanup(s.Close)
+	return s, gotapo.Connect(s.Host, "cam", "secret", options...)
+}
+
+func hasPreset(s *gotapotest.Server, name string) bool {
+	for _, v := range s.Presets() {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
+
+func TestPrivacyPark(t *testing.T) {
+	s, c := connect(t, true)
+	c.Settings.PrivacyPark.Value = true
+	if err := c.Move(30, 20); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SetPrivacy(true); err != nil {
+		t.Fatal(err)
+	}
+	if v := s.Value("lens_mask", "lens_mask_info", "enabled"); v != "on" {
+		t.Fatalf("lens mask %v, want on", v)
+	}
+	if x, y := s.Position(); x != 30 || y != -40 {
+		t.Fatalf("parked at %d,%d, want 30,-40", x, y)
+	}
+	if !hasPreset(s, gotapo.PrivacyPresetName) {
+		t.Fatal("position before privacy not saved")
+	}
+	if err := c.SetPrivacy(false); err != nil {
+		t.Fatal(err)
+	}
+	if v := s.Value("lens_mask", "lens_mask_info", "enabled"); v != "off" {
+		t.Fatalf("lens mask %v, want off", v)
+	}
+	if x, y := s.Position(); x != 30 || y != 20 {
+		t.Fatalf("returned to %d,%d, want 30,20", x, y)
+	}
+	if hasPreset(s, gotapo.PrivacyPresetName) {
+		t.Fatal("temporary preset not removed")
+	}
+}
+
+func TestPrivacyParkOverwriteStalePreset(t *testing.T) {
+	s, c := connect(t, true)
+	c.Settings.PrivacyPark.Value = true
+	if err := c.Move(-50, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SetPrivacy(true); err != nil {
+		t.Fatal(err)
+	}
+	// client lost, privacy turned off in app, preset of park is left
+	s.SetValue("lens_mask", "lens_mask_info", "enabled", "off")
+	c = gotapo.Connect(s.Host, "cam", "secret")
+	c.Settings.PrivacyPark.Value = true
+	if err := c.Move(80, 50); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SetPrivacy(true); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SetPrivacy(false); err != nil {
+		t.Fatal(err)
+	}
+	if x, y := s.Position(); x != 30 || y != 10 {
+		t.Fatalf("returned to %d,%d, want 30,10", x, y)
+	}
+}
+
+func TestPrivacyParkFailed(t *testing.T) {
+	s, c := connect(t, true)
+	c.Settings.PrivacyPark.Value = true
+	c.Settings.PrivacyParkPreset = "99"
+	err := c.SetPrivacy(true)
+	if !errors.Is(err, gotapo.ErrPresetNotFound) {
+		t.Fatalf("error %v, want preset not found", err)
+	}
+	if v := s.Value("lens_mask", "lens_mask_info", "enabled"); v != "off" {
+		t.Fatalf("lens mask %v without parked lens, want off", v)
+	}
+}
@@ -0,0 +1,560 @@
+// Package gotapotest working
+// as fake camera tapo over https
+// for testing code with gotapo without camera in LAN
+package gotapotest
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Error codes of cam
+const (
+	ErrorSession     = -40401
+	ErrorNonce       = -40413
+	ErrorUnsupported = -40210
+	ErrorParameter   = -40106
+	ErrorPrivacy     = -64324
+	ErrorPreset      = -64302
+	ErrorLocked      = -40404
+)
+
+// Fault is error injected in answer of fake cam
+type Fault int
+
+const (
+	// FaultAuth fail next login
+	FaultAuth Fault = iota + 1
+
+	// FaultExpiredStok expire session on next request
+	FaultExpiredStok
+
+	// FaultTimeout hold next request (login too) on Delay and close connection without answer
+	FaultTimeout
+
+	// FaultMalformed answer next request after login with broken JSON
+	FaultMalformed
+
+	// FaultDrop do next request after login, but close connection without answer
+	FaultDrop
+
+	// FaultSearch answer next search of detections with error (like without SD card)
+	FaultSearch
+)
+
+// Server is fake cam. Secure - firmware >= 1.3.9 (SHA-256 nonce login with
+// securePassthrough), otherwise legacy MD5 login
+type Server struct {
+	*httptest.Server
+	Host     string
+	User     string
+	Password string
+	Secure   bool
+	Delay    time.Duration
+
+	// MaxAttempts of wrong login before lock on LockTime (0 - without lock)
+	MaxAttempts int
+	LockTime    time.Duration
+
+	// TableLimit is max rows of table like region_info (0 - without limit)
+	TableLimit int
+
+	mu         sync.Mutex
+	store      map[string]map[string]map[string]any
+	tables     map[string]map[string][]map[string]any
+	components []map[string]any
+	sirenTypes []any
+	detections []Detection
+	missing    map[string]bool
+	presets    []preset
+	x, y       int
+	moves      []Move
+	reboots    int
+	logins     int
+	requests   []Request
+	faults     map[Fault]int
+	stok       string
+	nonce      string
+	key        []byte
+	iv         []byte
+	seq        int
+	failed     int
+	locked     time.Time
+}
+
+// Move is move of motor of fake cam
+type Move struct {
+	X         int
+	Y         int
+	Direction string
+}
+
+// Detection is event in detection list of fake cam. Zero End - in progress
+type Detection struct {
+	Code  int
+	Start time.Time
+	End   time.Time
+}
+
+// Request is request to fake cam after login. Methods is method of request or
+// methods inside multipleRequest
+type Request struct {
+	Methods []string
+	Header  http.Header
+	Time    time.Time
+}
+
+type preset struct {
+	ID   string
+	Name string
+	X    int
+	Y    int
+}
+
+// NewServer start fake cam. Close it after usage
+func NewServer(user, password string, secure bool) *Server {
+	s := &Server{
+		User:     user,
+		Password: password,
+		Secure:   secure,
+		Delay:    time.Second,
+		LockTime: 30 * time.Minute,
+		faults:   map[Fault]int{},
+		missing:  map[string]bool{},
+		seq:      100,
+	}
+	s.reset()
+	s.Server = httptest.NewTLSServer(http.HandlerFunc(s.handle))
+	s.Host = strings.TrimPrefix(s.Server.URL, "https://")
+	return s
+}
+
+// Fail inject fault in next count requests which it matches
+func (s *Server) Fail(fault Fault, count int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults[fault] += count
+}
+
+// Value of config of fake cam like Value("led", "config", "enabled")
+func (s *Server) Value(module, section, key string) any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.store[module][section][key]
+}
+
+// SetValue change config of fake cam
+func (s *Server) SetValue(module, section, key string, value any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.section(module, section)[key] = value
+}
+
+// Position of motor of fake cam
+func (s *Server) Position() (int, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.x, s.y
+}
+
+// Moves of motor of fake cam
+func (s *Server) Moves() []Move {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Move{}, s.moves...)
+}
+
+// Presets of fake cam as id - name
+func (s *Server) Presets() map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m := map[string]string{}
+	for _, v := range s.presets {
+		m[v.ID] = v.Name
+	}
+	return m
+}
+
+// AddPreset save current position of fake cam as preset and return its id
+func (s *Server) AddPreset(name string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addPreset(name)
+}
+
+// Detect add detection to list of fake cam. Detection with same Code and
+// Start is replaced (to finish detection in progress)
+func (s *Server) Detect(d Detection) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, v := range s.detections {
+		if v.Code == d.Code && v.Start.Equal(d.Start) {
+			s.detections[k] = d
+			return
+		}
+	}
+	s.detections = append(s.detections, d)
+}
+
+// Unsupported make methods unknown for fake cam (like firmware without them)
+func (s *Server) Unsupported(methods ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, v := range methods {
+		s.missing[v] = true
+	}
+}
+
+// RemoveComponents remove components from list of fake cam (like other model)
+func (s *Server) RemoveComponents(names ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := []map[string]any{}
+	for _, v := range s.components {
+		found := false
+		for _, name := range names {
+			found = found || v["name"] == name
+		}
+		if !found {
+			list = append(list, v)
+		}
+	}
+	s.components = list
+}
+
+// Reboots count of fake cam
+func (s *Server) Reboots() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.reboots
+}
+
+// Logins count of successful logins
+func (s *Server) Logins() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.logins
+}
+
+// Requests to fake cam after login
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request{}, s.requests...)
+}
+
+// Take one fault
+func (s *Server) fault(f Fault) bool {
+	if s.faults[f] > 0 {
+		s.faults[f]--
+		return true
+	}
+	return false
+}
+
+func hashSha256(value string) string {
+	return strings.ToUpper(fmt.Sprintf("%x", sha256.Sum256([]byte(value))))
+}
+
+func hashMD5(value string) string {
+	return strings.ToUpper(fmt.Sprintf("%x", md5.Sum([]byte(value))))
+}
+
+func random() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return strings.ToUpper(hex.EncodeToString(b))
+}
+
+func encrypt(text, key, iv []byte) string {
+	pad := aes.BlockSize - len(text)%aes.BlockSize
+	text = append(text, bytes.Repeat([]byte{byte(pad)}, pad)...)
+	block, _ := aes.NewCipher(key)
+	encoded := make([]byte, len(text))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(encoded, text)
+	return base64.StdEncoding.EncodeToString(encoded)
+}
+
+func decrypt(text string, key, iv []byte) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(text)
+	if err != nil || len(data) == 0 || len(data)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("bad request")
+	}
+	block, _ := aes.NewCipher(key)
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(data, data)
+	pad := int(data[len(data)-1])
+	if pad == 0 || pad > aes.BlockSize {
+		return nil, fmt.Errorf("bad padding")
+	}
+	return data[:len(data)-pad], nil
+}
+
+func write(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	json.NewEncoder(w).Encode(v)
+}
+
+func errorCode(code int) map[string]any {
+	return map[string]any{"error_code": code}
+}
+
+// handle all requests to fake cam
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	s.mu.Lock()
+	if s.fault(FaultTimeout) {
+		s.mu.Unlock()
+		time.Sleep(s.Delay)
+		drop(w)
+		return
+	}
+	defer s.mu.Unlock()
+	request := map[string]any{}
+	if err := json.Unmarshal(body, &request); err != nil {
+		write(w, errorCode(ErrorParameter))
+		return
+	}
+	if r.URL.Path == "/" || r.URL.Path == "" {
+		write(w, s.login(request))
+		return
+	}
+	if s.stok == "" || r.URL.Path != "/stok="+s.stok+"/ds" {
+		write(w, errorCode(ErrorSession))
+		return
+	}
+	if s.fault(FaultMalformed) {
+		w.Write([]byte(`{"error_code":0,"result":`))
+		return
+	}
+	if s.fault(FaultExpiredStok) {
+		s.stok = ""
+		write(w, errorCode(ErrorSession))
+		return
+	}
+	var ret map[string]any
+	switch {
+	case request["method"] == "securePassthrough":
+		ret = s.passthrough(r, body, request)
+	case s.Secure:
+		ret = errorCode(ErrorSession)
+	default:
+		ret = s.request(r, request)
+	}
+	if s.fault(FaultDrop) {
+		drop(w)
+		return
+	}
+	write(w, ret)
+}
+
+// close connection without answer
+func drop(w http.ResponseWriter) {
+	if hj, ok := w.(http.Hijacker); ok {
+		if conn, _, err := hj.Hijack(); err == nil {
+			conn.Close()
+		}
+	}
+}
+
+// login by legacy and secure ways
+func (s *Server) login(request map[string]any) map[string]any {
+	params, _ := request["params"].(map[string]any)
+	user, _ := params["username"].(string)
+	if request["method"] != "login" {
+		return errorCode(ErrorSession)
+	}
+	if left := time.Until(s.locked); left > 0 {
+		return lockout(ErrorLocked, int(left.Seconds()), 0, s.MaxAttempts)
+	}
+	if s.fault(FaultAuth) {
+		return errorCode(ErrorSession)
+	}
+	if _, ok := params["encrypt_type"]; ok {
+		if !s.Secure {
+			return errorCode(ErrorUnsupported)
+		}
+		digest, _ := params["digest_passwd"].(string)
+		if digest == "" {
+			s.nonce = random()
+			return map[string]any{
+				"error_code": ErrorNonce,
+				"result": map[string]any{
+					"data": map[string]any{
+						"code":           ErrorNonce,
+						"encrypt_type":   []string{"3"},
+						"key":            random(),
+						"nonce":          s.nonce,
+						"device_confirm": hashSha256(s.nonce + hashSha256(s.Password)),
+					},
+				},
+			}
+		}
+		hashPass := hashSha256(hashSha256(s.Password) + s.nonce)
+		if user != s.User || s.nonce == "" || digest != hashPass+s.nonce {
+			return s.refuse()
+		}
+		key := sha256.Sum256([]byte("lsk" + s.nonce + hashPass))
+		iv := sha256.Sum256([]byte("ivb" + s.nonce + hashPass))
+		s.key = key[:aes.BlockSize]
+		s.iv = iv[:aes.BlockSize]
+		s.nonce = ""
+		return s.session(true)
+	}
+	password, _ := params["password"].(string)
+	switch {
+	case user != s.User:
+	case s.Secure && password == hashSha256(s.Password):
+		return s.session(true)
+	case !s.Secure && password == hashMD5(s.Password):
+		return s.session(false)
+	}
+	return s.refuse()
+}
+
+// wrong login, lock after MaxAttempts
+func (s *Server) refuse() map[string]any {
+	if s.MaxAttempts <= 0 {
+		return errorCode(ErrorSession)
+	}
+	s.failed++
+	if s.failed >= s.MaxAttempts {
+		s.failed = 0
+		s.locked = time.Now().Add(s.LockTime)
+		return lockout(ErrorLocked, int(s.LockTime.Seconds()), 0, s.MaxAttempts)
+	}
+	return lockout(ErrorSession, 0, s.MaxAttempts-s.failed, s.MaxAttempts)
+}
+
+func lockout(code, secLeft, attempts, maxAttempts int) map[string]any {
+	return map[string]any{
+		"error_code": ErrorSession,
+		"result": map[string]any{
+			"data": map[string]any{
+				"code":     code,
+				"sec_left": secLeft,
+				"time":     attempts,
+				"max_time": maxAttempts,
+			},
+		},
+	}
+}
+
+// new session
+func (s *Server) session(secure bool) map[string]any {
+	s.stok = random()
+	s.logins++
+	s.failed = 0
+	result := map[string]any{
+		"stok":       s.stok,
+		"user_group": "root",
+	}
+	if secure {
+		result["start_seq"] = s.seq
+	}
+	return map[string]any{"error_code": 0, "result": result}
+}
+
+// encrypted request
+func (s *Server) passthrough(r *http.Request, body []byte, request map[string]any) map[string]any {
+	if s.key == nil {
+		return errorCode(ErrorSession)
+	}
+	params, _ := request["params"].(map[string]any)
+	encoded, _ := params["request"].(string)
+	tag := hashSha256(hashSha256(hashSha256(s.Password)) + string(body) + r.Header.Get("Seq"))
+	if r.Header.Get("Tapo_tag") != tag {
+		return errorCode(ErrorSession)
+	}
+	plain, err := decrypt(encoded, s.key, s.iv)
+	if err != nil {
+		return errorCode(ErrorParameter)
+	}
+	inner := map[string]any{}
+	if err := json.Unmarshal(plain, &inner); err != nil {
+		return errorCode(ErrorParameter)
+	}
+	response, _ := json.Marshal(s.request(r, inner))
+	s.seq++
+	return map[string]any{
+		"error_code": 0,
+		"seq":        s.seq,
+		"result": map[string]any{
+			"response": encrypt(response, s.key, s.iv),
+		},
+	}
+}
+
+// request after login (single or multipleRequest)
+func (s *Server) request(r *http.Request, request map[string]any) map[string]any {
+	method, _ := request["method"].(string)
+	item := Request{Methods: []string{method}, Header: r.Header.Clone(), Time: time.Now()}
+	if method == "multipleRequest" {
+		params, _ := request["params"].(map[string]any)
+		list, _ := params["requests"].([]any)
+		item.Methods = nil
+		for _, v := range list {
+			inner, _ := v.(map[string]any)
+			name, _ := inner["method"].(string)
+			item.Methods = append(item.Methods, name)
+		}
+	}
+	s.requests = append(s.requests, item)
+	if method != "multipleRequest" {
+		result, code := s.call(method, request)
+		ret := map[string]any{"error_code": code}
+		for k, v := range result {
+			ret[k] = v
+		}
+		return ret
+	}
+	params, _ := request["params"].(map[string]any)
+	list, _ := params["requests"].([]any)
+	responses := []any{}
+	for _, v := range list {
+		item, _ := v.(map[string]any)
+		name, _ := item["method"].(string)
+		inner, _ := item["params"].(map[string]any)
+		result, code := s.call(name, inner)
+		if result == nil {
+			result = map[string]any{}
+		}
+		responses = append(responses, map[string]any{
+			"method":     name,
+			"result":     result,
+			"error_code": code,
+		})
+	}
+	return map[string]any{
+		"error_code": 0,
+		"result":     map[string]any{"responses": responses},
+	}
+}
+
+// number from string or number
+func number(v any) int {
+	switch val := v.(type) {
+	case int:
+		return val
+	case float64:
+		return int(val)
+	case string:
+		n, _ := strconv.Atoi(val)
+		return n
+	}
+	return 0
+}
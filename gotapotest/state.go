@@ -0,0 +1,618 @@
+package gotapotest
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Default state of fake cam (C200 after reset)
+func (s *Server) reset() {
+	allDay := `["0000-2400:2"]`
+	s.store = map[string]map[string]map[string]any{
+		"device_info": {
+			"basic_info": {
+				"device_type":  "SMART.IPCAMERA",
+				"device_model": "C200",
+				"device_name":  "C200 2.0",
+				"device_info":  "C200 2.0 IPC",
+				"hw_version":   "2.0",
+				"sw_version":   "1.3.11 Build 231012 Rel.71764n",
+				"device_alias": "gotapotest",
+				"mac":          "00-11-22-33-44-55",
+				"dev_id":       "80220000000000000000000000000000000000G0",
+				"oem_id":       "00000000000000000000000000000000",
+			},
+		},
+		"lens_mask": {
+			"lens_mask_info": {"enabled": "off"},
+		},
+		"led": {
+			"config": {"enabled": "on"},
+		},
+		"motion_detection": {
+			"motion_det": {"enabled": "on", "sensitivity": "medium", "digital_sensitivity": "50"},
+		},
+		"people_detection": {
+			"detection": {"enabled": "off", "sensitivity": "50"},
+		},
+		"vehicle_detection": {
+			"detection": {"enabled": "off", "sensitivity": "50"},
+		},
+		"pet_detection": {
+			"detection": {"enabled": "off", "sensitivity": "50"},
+		},
+		"bark_detection": {
+			"detection": {"enabled": "off", "sensitivity": "50"},
+		},
+		"meow_detection": {
+			"detection": {"enabled": "off", "sensitivity": "50"},
+		},
+		"glass_detection": {
+			"detection": {"enabled": "off", "sensitivity": "50"},
+		},
+		"sound_detection": {
+			"bcd": {"enabled": "off", "sensitivity": "medium"},
+		},
+		"tamper_detection": {
+			"tamper_det": {"enabled": "off", "sensitivity": "medium"},
+		},
+		"linecrossing_detection": {
+			"detection": {"enabled": "off", "sensitivity": "50"},
+		},
+		"intrusion_detection": {
+			"detection": {"enabled": "off", "sensitivity": "50"},
+		},
+		"target_track": {
+			"target_track_info": {"enabled": "off"},
+		},
+		"OSD": {
+			"date":   {"enabled": "on", "x_coor": "0", "y_coor": "0"},
+			"week":   {"enabled": "off", "x_coor": "0", "y_coor": "0"},
+			"font":   {"display": "ntnb", "size": "auto", "color_type": "auto", "color": "white"},
+			"osd_ex": {"enabled": "off"},
+		},
+		"image": {
+			"switch": {
+				"switch_mode":         "common",
+				"schedule_start_time": "64800",
+				"schedule_end_time":   "21600",
+				"flip_type":           "off",
+				"rotate_type":         "off",
+				"ldc":                 "off",
+				"night_vision_mode":   "inf_night_vision",
+				"wtl_intensity_level": "5",
+			},
+			"common": {
+				"luma":            "50",
+				"contrast":        "50",
+				"chroma":          "50",
+				"saturation":      "50",
+				"sharpness":       "50",
+				"exp_type":        "auto",
+				"shutter":         "1/25",
+				"focus_type":      "semi_auto",
+				"focus_limited":   "600",
+				"exp_gain":        "0",
+				"inf_start_time":  "64800",
+				"inf_end_time":    "21600",
+				"inf_sensitivity": "4",
+				"inf_delay":       "5",
+				"wide_dynamic":    "off",
+				"light_freq_mode": "auto",
+				"wd_gain":         "50",
+				"wb_type":         "auto",
+				"smartir":         "auto_ir",
+				"smartir_level":   "100",
+				"dehaze":          "off",
+				"inf_type":        "auto",
+			},
+			"capability": {"supported_light_freq_mode": []any{"auto", "50", "60"}},
+		},
+		"msg_alarm": {
+			"chn1_msg_alarm_info": {
+				"enabled":        "off",
+				"alarm_type":     "0",
+				"light_type":     "0",
+				"alarm_mode":     []any{"sound", "light"},
+				"alarm_volume":   "high",
+				"alarm_duration": "0",
+			},
+			"siren": {"status": "off", "siren_type": "Alarm", "siren_duration": 30, "start": 0},
+		},
+		"msg_alarm_plan": {
+			"chn1_msg_alarm_plan": {"enabled": "off", "alarm_plan_num": "0"},
+		},
+		"record_plan": {
+			"chn1_channel": {
+				"enabled":   "on",
+				"sunday":    allDay,
+				"monday":    allDay,
+				"tuesday":   allDay,
+				"wednesday": allDay,
+				"thursday":  allDay,
+				"friday":    allDay,
+				"saturday":  allDay,
+			},
+		},
+		"system": {
+			"clock_status":    {},
+			"last_alarm_info": {"last_alarm_type": "", "last_alarm_time": "0"},
+		},
+	}
+	s.tables = map[string]map[string][]map[string]any{
+		"OSD": {
+			"label_info": {
+				{"label_info_1": map[string]any{"enabled": "off", "text": "", "x_coor": "0", "y_coor": "450"}},
+				{"label_info_2": map[string]any{"enabled": "off", "text": "", "x_coor": "0", "y_coor": "0"}},
+				{"label_info_3": map[string]any{"enabled": "off", "text": "", "x_coor": "0", "y_coor": "0"}},
+			},
+		},
+	}
+	s.presets = []preset{
+		{ID: "1", Name: "Door", X: -90, Y: 0},
+		{ID: "2", Name: "Window", X: 90, Y: 10},
+	}
+	s.components = []map[string]any{
+		{"name": "detection", "version": 3},
+		{"name": "personDetection", "version": 2},
+		{"name": "vehicleDetection", "version": 1},
+		{"name": "petDetection", "version": 1},
+		{"name": "babyCryDetection", "version": 1},
+		{"name": "tamperDetection", "version": 1},
+		{"name": "linecrossingDetection", "version": 1},
+		{"name": "intrusionDetection", "version": 1},
+		{"name": "lensMask", "version": 2},
+		{"name": "ptz", "version": 1},
+		{"name": "osd", "version": 2},
+		{"name": "led", "version": 1},
+		{"name": "siren", "version": 1},
+		{"name": "nightVisionMode", "version": 1},
+	}
+	s.sirenTypes = []any{"Alarm", "Siren", "Emergency", "Red Alert", "Classic Bell", "Doorbell Tone"}
+	s.x, s.y = 0, 0
+}
+
+// Detections started in time range of search
+func (s *Server) searchDetections(params map[string]any) map[string]any {
+	playback, _ := params["playback"].(map[string]any)
+	search, _ := playback["search_detection_list"].(map[string]any)
+	from, to := int64(number(search["start_time"])), int64(number(search["end_time"]))
+	list := []any{}
+	for _, v := range s.detections {
+		if v.Start.Unix() < from || v.Start.Unix() > to {
+			continue
+		}
+		end := int64(0)
+		if !v.End.IsZero() {
+			end = v.End.Unix()
+		}
+		list = append(list, map[string]any{
+			"start_time": v.Start.Unix(),
+			"end_time":   end,
+			"alarm_type": v.Code,
+		})
+	}
+	return map[string]any{"playback": map[string]any{"search_detection_list": list}}
+}
+
+// Section of store, created if not exist
+func (s *Server) section(module, section string) map[string]any {
+	if s.store[module] == nil {
+		s.store[module] = map[string]map[string]any{}
+	}
+	if s.store[module][section] == nil {
+		s.store[module][section] = map[string]any{}
+	}
+	return s.store[module][section]
+}
+
+// One request (inside multipleRequest or not). Params of direct request is request itself
+func (s *Server) call(method string, params map[string]any) (map[string]any, int) {
+	s.section("system", "clock_status")["seconds_from_1970"] = time.Now().Unix()
+	s.section("system", "clock_status")["local_time"] = time.Now().Format("2006-01-02 15:04:05")
+	if s.missing[method] {
+		return nil, ErrorUnsupported
+	}
+	switch method {
+	case "getPresetConfig":
+		return s.presetConfig(), 0
+	case "getAppComponentList":
+		return map[string]any{"app_component": map[string]any{"app_component_list": s.components}}, 0
+	case "getSirenTypeList":
+		return map[string]any{"msg_alarm": map[string]any{"siren_type_list": s.sirenTypes}}, 0
+	case "getLightTypeList":
+		return map[string]any{"msg_alarm": map[string]any{"light_type_list": []any{"0", "1"}}}, 0
+	case "getSirenStatus":
+		siren := s.section("msg_alarm", "siren")
+		left := 0
+		if siren["status"] == "on" {
+			left = number(siren["siren_duration"]) - int(time.Now().Unix()-int64(number(siren["start"])))
+			if left <= 0 {
+				siren["status"], left = "off", 0
+			}
+		}
+		return map[string]any{"status": siren["status"], "time_left": left}, 0
+	case "setSirenConfig", "setSirenStatus":
+		values, _ := params["msg_alarm"].(map[string]any)
+		siren := s.section("msg_alarm", "siren")
+		if v, ok := values["siren_type"]; ok {
+			if !contains(s.sirenTypes, v) {
+				return nil, ErrorParameter
+			}
+			siren["siren_type"] = v
+		}
+		if v, ok := values["siren_duration"]; ok {
+			siren["siren_duration"] = number(v)
+		}
+		if v, ok := values["status"]; ok {
+			siren["status"] = v
+			siren["start"] = int(time.Now().Unix())
+		}
+		return map[string]any{}, 0
+	case "getLightFrequencyCapability":
+		supported := s.section("image", "capability")["supported_light_freq_mode"]
+		return map[string]any{"image": map[string]any{"common": map[string]any{"supported_light_freq_mode": supported}}}, 0
+	case "getWhitelampStatus":
+		lamp := s.section("image", "switch")
+		left := 0
+		if lamp["force_wtl_state"] == "on" && number(lamp["wtl_force_time"]) > 0 {
+			left = number(lamp["wtl_force_time"]) - int(time.Now().Unix()-int64(number(lamp["wtl_force_start"])))
+			if left <= 0 {
+				lamp["force_wtl_state"], left = "off", 0
+			}
+		}
+		status := 0
+		if lamp["force_wtl_state"] == "on" {
+			status = 1
+		}
+		return map[string]any{"status": status, "rest_time": left}, 0
+	case "setForceWhitelampState":
+		values, _ := params["image"].(map[string]any)
+		request, _ := values["switch"].(map[string]any)
+		lamp := s.section("image", "switch")
+		lamp["force_wtl_state"] = request["force_wtl_state"]
+		lamp["wtl_force_time"] = number(request["wtl_force_time"])
+		lamp["wtl_force_start"] = int(time.Now().Unix())
+		return map[string]any{}, 0
+	case "searchDetectionList":
+		if s.fault(FaultSearch) {
+			return nil, ErrorUnsupported
+		}
+		return s.searchDetections(params), 0
+	case "do":
+		return s.do(params)
+	case "add":
+		return s.add(params)
+	case "delete":
+		return s.remove(params)
+	}
+	switch {
+	case method == "get" || strings.HasPrefix(method, "get"):
+		return s.get(params)
+	case method == "set" || strings.HasPrefix(method, "set"):
+		return s.set(params)
+	}
+	return nil, ErrorUnsupported
+}
+
+// Modules of request without "method"
+func modules(params map[string]any) map[string]map[string]any {
+	m := map[string]map[string]any{}
+	for k, v := range params {
+		if values, ok := v.(map[string]any); ok && k != "method" {
+			m[k] = values
+		}
+	}
+	return m
+}
+
+// Names from string or list
+func names(v any) []string {
+	switch val := v.(type) {
+	case string:
+		return []string{val}
+	case []any:
+		list := []string{}
+		for _, name := range val {
+			if str, ok := name.(string); ok {
+				list = append(list, str)
+			}
+		}
+		return list
+	}
+	return nil
+}
+
+func contains(list []any, v any) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
+
+func copyValues(values map[string]any) map[string]any {
+	m := map[string]any{".name": "", ".type": ""}
+	for k, v := range values {
+		m[k] = v
+	}
+	return m
+}
+
+// Read sections and tables of modules
+func (s *Server) get(params map[string]any) (map[string]any, int) {
+	result := map[string]any{}
+	for module, request := range modules(params) {
+		sections, ok := s.store[module]
+		if !ok {
+			return nil, ErrorParameter
+		}
+		ret := map[string]any{}
+		list := names(request["name"])
+		tables := names(request["table"])
+		if len(list) == 0 && len(tables) == 0 {
+			for k := range sections {
+				list = append(list, k)
+			}
+		}
+		for _, name := range list {
+			values, ok := sections[name]
+			if !ok {
+				return nil, ErrorParameter
+			}
+			values = copyValues(values)
+			values[".name"], values[".type"] = name, name
+			ret[name] = values
+		}
+		for _, table := range tables {
+			rows := []map[string]any{}
+			rows = append(rows, s.tables[module][table]...)
+			ret[table] = rows
+		}
+		result[module] = ret
+	}
+	return result, 0
+}
+
+// Write sections and tables of modules
+func (s *Server) set(params map[string]any) (map[string]any, int) {
+	for module, request := range modules(params) {
+		if _, ok := s.store[module]; !ok {
+			return nil, ErrorParameter
+		}
+		for name, v := range request {
+			switch values := v.(type) {
+			case []any:
+				rows := []map[string]any{}
+				for _, row := range values {
+					if m, ok := row.(map[string]any); ok {
+						rows = append(rows, m)
+					}
+				}
+				s.table(module)[name] = rows
+			case map[string]any:
+				if row := s.row(module, name); row != nil {
+					for k, val := range values {
+						row[k] = val
+					}
+					continue
+				}
+				section := s.section(module, name)
+				for k, val := range values {
+					section[k] = val
+				}
+			default:
+				return nil, ErrorParameter
+			}
+		}
+	}
+	return map[string]any{}, 0
+}
+
+func (s *Server) table(module string) map[string][]map[string]any {
+	if s.tables[module] == nil {
+		s.tables[module] = map[string][]map[string]any{}
+	}
+	return s.tables[module]
+}
+
+// Row of table by name like "label_info_1"
+func (s *Server) row(module, name string) map[string]any {
+	for _, rows := range s.tables[module] {
+		for _, row := range rows {
+			if values, ok := row[name].(map[string]any); ok {
+				return values
+			}
+		}
+	}
+	return nil
+}
+
+// Add rows of table with names like "region_info_1"
+func (s *Server) add(params map[string]any) (map[string]any, int) {
+	for module, request := range modules(params) {
+		if _, ok := s.store[module]; !ok {
+			return nil, ErrorParameter
+		}
+		for table, v := range request {
+			list, ok := v.([]any)
+			if !ok {
+				return nil, ErrorParameter
+			}
+			for _, values := range list {
+				rows := s.table(module)[table]
+				if s.TableLimit > 0 && len(rows) >= s.TableLimit {
+					return nil, ErrorParameter
+				}
+				n := 1
+				for s.row(module, table+"_"+strconv.Itoa(n)) != nil {
+					n++
+				}
+				s.table(module)[table] = append(rows, map[string]any{table + "_" + strconv.Itoa(n): values})
+			}
+		}
+	}
+	return map[string]any{}, 0
+}
+
+// Remove rows of table by names
+func (s *Server) remove(params map[string]any) (map[string]any, int) {
+	for module, request := range modules(params) {
+		for table, v := range request {
+			values, _ := v.(map[string]any)
+			for _, name := range names(values["name"]) {
+				if s.row(module, name) == nil {
+					return nil, ErrorParameter
+				}
+				rows := []map[string]any{}
+				for _, row := range s.table(module)[table] {
+					if _, ok := row[name]; !ok {
+						rows = append(rows, row)
+					}
+				}
+				s.table(module)[table] = rows
+			}
+		}
+	}
+	return map[string]any{}, 0
+}
+
+// Actions of cam: motor, presets, reboot
+func (s *Server) do(params map[string]any) (map[string]any, int) {
+	all := modules(params)
+	if motor, ok := all["motor"]; ok {
+		if s.section("lens_mask", "lens_mask_info")["enabled"] == "on" {
+			return nil, ErrorPrivacy
+		}
+		if move, ok := motor["move"].(map[string]any); ok {
+			x, y := number(move["x_coord"]), number(move["y_coord"])
+			s.moveTo(s.x+x, s.y+y, Move{X: x, Y: y})
+			return map[string]any{}, 0
+		}
+		if step, ok := motor["movestep"].(map[string]any); ok {
+			direction, _ := step["direction"].(string)
+			x, y := 0, 0
+			switch direction {
+			case "0":
+				x = 10
+			case "90":
+				y = 10
+			case "180":
+				x = -10
+			case "270":
+				y = -10
+			default:
+				return nil, ErrorParameter
+			}
+			s.moveTo(s.x+x, s.y+y, Move{X: x, Y: y, Direction: direction})
+			return map[string]any{}, 0
+		}
+		return nil, ErrorParameter
+	}
+	if p, ok := all["preset"]; ok {
+		if v, ok := p["goto_preset"].(map[string]any); ok {
+			id, _ := v["id"].(string)
+			for _, item := range s.presets {
+				if item.ID == id {
+					if s.section("lens_mask", "lens_mask_info")["enabled"] == "on" {
+						return nil, ErrorPrivacy
+					}
+					s.moveTo(item.X, item.Y, Move{X: item.X - s.x, Y: item.Y - s.y})
+					return map[string]any{}, 0
+				}
+			}
+			return nil, ErrorPreset
+		}
+		if v, ok := p["set_preset"].(map[string]any); ok {
+			name, _ := v["name"].(string)
+			return map[string]any{"id": s.addPreset(name)}, 0
+		}
+		if v, ok := p["remove_preset"].(map[string]any); ok {
+			ids := names(v["id"])
+			list := []preset{}
+			for _, item := range s.presets {
+				found := false
+				for _, id := range ids {
+					found = found || id == item.ID
+				}
+				if !found {
+					list = append(list, item)
+				}
+			}
+			if len(list)+len(ids) != len(s.presets) {
+				return nil, ErrorPreset
+			}
+			s.presets = list
+			return map[string]any{}, 0
+		}
+		return nil, ErrorParameter
+	}
+	if system, ok := all["system"]; ok {
+		if _, ok := system["reboot"]; ok {
+			s.reboots++
+			s.stok = ""
+			return map[string]any{}, 0
+		}
+	}
+	return nil, ErrorUnsupported
+}
+
+// Move motor inside limits of C200
+func (s *Server) moveTo(x, y int, m Move) {
+	s.x = clamp(x, -170, 170)
+	s.y = clamp(y, -40, 180)
+	s.moves = append(s.moves, m)
+}
+
+func clamp(v, low, high int) int {
+	if v < low {
+		return low
+	}
+	if v > high {
+		return high
+	}
+	return v
+}
+
+// Save current position as preset with next id
+func (s *Server) addPreset(name string) string {
+	id := 1
+	for _, item := range s.presets {
+		if n, _ := strconv.Atoi(item.ID); n >= id {
+			id = n + 1
+		}
+	}
+	s.presets = append(s.presets, preset{ID: strconv.Itoa(id), Name: name, X: s.x, Y: s.y})
+	return strconv.Itoa(id)
+}
+
+// Presets in format of getPresetConfig
+func (s *Server) presetConfig() map[string]any {
+	sort.Slice(s.presets, func(i, j int) bool {
+		a, _ := strconv.Atoi(s.presets[i].ID)
+		b, _ := strconv.Atoi(s.presets[j].ID)
+		return a < b
+	})
+	ids, list, pan, tilt, ro := []string{}, []string{}, []string{}, []string{}, []string{}
+	for _, v := range s.presets {
+		ids = append(ids, v.ID)
+		list = append(list, v.Name)
+		pan = append(pan, strconv.FormatFloat(float64(v.X)/170, 'f', 6, 64))
+		tilt = append(tilt, strconv.FormatFloat(float64(v.Y)/180, 'f', 6, 64))
+		ro = append(ro, "0")
+	}
+	return map[string]any{
+		"preset": map[string]any{
+			"preset": map[string]any{
+				"id":            ids,
+				"name":          list,
+				"position_pan":  pan,
+				"position_tilt": tilt,
+				"read_only":     ro,
+			},
+		},
+	}
+}
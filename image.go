@@ -0,0 +1,190 @@
+package gotapo
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// ImageSettings is tunables of image (section image.common).
+// Luma, Contrast, Chroma, Saturation, Sharpness and ExpGain are 0-100.
+// Nil numbers and empty ExpType, Shutter and FocusType are not sent to cam
+// (and not read if cam haven't them)
+type ImageSettings struct {
+	Luma       *int
+	Contrast   *int
+	Chroma     *int
+	Saturation *int
+	Sharpness  *int
+	ExpType    string // "auto" or "manual"
+	Shutter    string // like "1/25"
+	ExpGain    *int
+	FocusType  string // "auto", "semi_auto" or "manual"
+}
+
+// type set image common
+type setImageCommon struct {
+	Method string `json:"method"`
+	Image  struct {
+		Common imageCommon `json:"common"`
+	} `json:"image"`
+}
+
+// type image common values
+type imageCommon struct {
+	Luma         string `json:"luma,omitempty"`
+	Contrast     string `json:"contrast,omitempty"`
+	Chroma       string `json:"chroma,omitempty"`
+	Saturation   string `json:"saturation,omitempty"`
+	Sharpness    string `json:"sharpness,omitempty"`
+	ExpType      string `json:"exp_type,omitempty"`
+	Shutter      string `json:"shutter,omitempty"`
+	ExpGain      string `json:"exp_gain,omitempty"`
+	FocusType    string `json:"focus_type,omitempty"`
+	InfType      string `json:"inf_type,omitempty"`
+	InfStartTime string `json:"inf_start_time,omitempty"`
+	InfEndTime   string `json:"inf_end_time,omitempty"`
+	LightFreq    string `json:"light_freq_mode,omitempty"`
+}
+
+// type image settings return (inside multipleRequest)
+type imageRet struct {
+	Image struct {
+		Switch struct {
+			FlipType          string `json:"flip_type"`
+			RotateType        string `json:"rotate_type"`
+			Ldc               string `json:"ldc"`
+			NightVisionMode   string `json:"night_vision_mode"`
+			WtlIntensityLevel string `json:"wtl_intensity_level"`
+		} `json:"switch"`
+		Common imageCommon `json:"common"`
+	} `json:"image"`
+}
+
+func setImageCommonTemplate(values ...any) setImageCommon {
+	t := setImageCommon{}
+	t.Method = MethodSet
+	t.Image.Common = values[0].(imageCommon)
+	return t
+}
+
+// shutter speed like 1/25
+var shutterRe = regexp.MustCompile(`^1/[0-9]+$`)
+
+// Read image and switch sections of cam
+func (o *Tapo) getImage() (*imageRet, error) {
+	result, err := o.multiple(ldcTemplate())
+	if err != nil {
+		return nil, err
+	}
+	ret := new(imageRet)
+	if err := json.Unmarshal(result.Result.Responses[0].Result, ret); err != nil {
+		return nil, ErrResponse
+	}
+	return ret, nil
+}
+
+// GetImageSettings read tunables of image from cam
+func (o *Tapo) GetImageSettings() (ImageSettings, error) {
+	ret, err := o.getImage()
+	if err != nil {
+		return ImageSettings{}, err
+	}
+	v := ret.Image.Common
+	return ImageSettings{
+		Luma:       atoiOrNil(v.Luma),
+		Contrast:   atoiOrNil(v.Contrast),
+		Chroma:     atoiOrNil(v.Chroma),
+		Saturation: atoiOrNil(v.Saturation),
+		Sharpness:  atoiOrNil(v.Sharpness),
+		ExpType:    v.ExpType,
+		Shutter:    v.Shutter,
+		ExpGain:    atoiOrNil(v.ExpGain),
+		FocusType:  v.FocusType,
+	}, nil
+}
+
+// SetImageSettings write tunables of image to cam. Only set fields are sent
+func (o *Tapo) SetImageSettings(s ImageSettings) error {
+	if err := s.validate(); err != nil {
+		return err
+	}
+	return o.apply(
+		setImageCommonTemplate(
+			imageCommon{
+				Luma:       itoaOrEmpty(s.Luma),
+				Contrast:   itoaOrEmpty(s.Contrast),
+				Chroma:     itoaOrEmpty(s.Chroma),
+				Saturation: itoaOrEmpty(s.Saturation),
+				Sharpness:  itoaOrEmpty(s.Sharpness),
+				ExpType:    s.ExpType,
+				Shutter:    s.Shutter,
+				ExpGain:    itoaOrEmpty(s.ExpGain),
+				FocusType:  s.FocusType,
+			},
+		),
+	)
+}
+
+// Number of cam, nil if cam haven't it
+func atoiOrNil(value string) *int {
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		return nil
+	}
+	return &v
+}
+
+// Number for cam, empty (not sent) if nil
+func itoaOrEmpty(v *int) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.Itoa(*v)
+}
+
+// UpdateImageSettings read tunables, change it by fn and write back
+func (o *Tapo) UpdateImageSettings(fn func(s *ImageSettings)) error {
+	o.config.Lock()
+	defer o.config.Unlock()
+	s, err := o.GetImageSettings()
+	if err != nil {
+		return err
+	}
+	fn(&s)
+	return o.SetImageSettings(s)
+}
+
+// Check ranges before send
+func (s ImageSettings) validate() error {
+	for _, v := range []struct {
+		name  string
+		value *int
+	}{
+		{"luma", s.Luma},
+		{"contrast", s.Contrast},
+		{"chroma", s.Chroma},
+		{"saturation", s.Saturation},
+		{"sharpness", s.Sharpness},
+		{"exposure gain", s.ExpGain},
+	} {
+		if v.value != nil && (*v.value < 0 || *v.value > 100) {
+			return fmt.Errorf("gotapo: image %s %d out of range 0-100", v.name, *v.value)
+		}
+	}
+	switch s.ExpType {
+	case "", "auto", "manual":
+	default:
+		return fmt.Errorf("gotapo: unknown exposure type %q", s.ExpType)
+	}
+	if s.Shutter != "" && !shutterRe.MatchString(s.Shutter) {
+		return fmt.Errorf("gotapo: shutter %q not like 1/25", s.Shutter)
+	}
+	switch s.FocusType {
+	case "", "auto", "semi_auto", "manual":
+	default:
+		return fmt.Errorf("gotapo: unknown focus type %q", s.FocusType)
+	}
+	return nil
+}
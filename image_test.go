@@ -0,0 +1,54 @@
+package gotapo_test
+
+import (
+	"testing"
+
+	"github.com/KusoKaihatsuSha/gotapo"
+)
+
+func TestSetImageSettingsOnlySet(t *testing.T) {
+	s, c := connect(t, true)
+	s.SetValue("image", "common", "contrast", "70")
+	luma := 60
+	if err := c.SetImageSettings(gotapo.ImageSettings{Luma: &luma}); err != nil {
+		t.Fatal(err)
+	}
+	if v := s.Value("image", "common", "luma"); v != "60" {
+		t.Fatalf("luma %v, want 60", v)
+	}
+	if v := s.Value("image", "common", "contrast"); v != "70" {
+		t.Fatalf("contrast %v, want kept 70", v)
+	}
+	if v := s.Value("image", "common", "exp_type"); v != "auto" {
+		t.Fatalf("exposure type %v, want kept auto", v)
+	}
+}
+
+func TestUpdateImageSettingsMissingField(t *testing.T) {
+	s, c := connect(t, true)
+	s.SetValue("image", "common", "sharpness", nil)
+	err := c.UpdateImageSettings(func(v *gotapo.ImageSettings) {
+		if v.Sharpness != nil {
+			t.Errorf("sharpness %d, want nil", *v.Sharpness)
+		}
+		chroma := 0
+		v.Chroma = &chroma
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := s.Value("image", "common", "chroma"); v != "0" {
+		t.Fatalf("chroma %v, want 0", v)
+	}
+	if v := s.Value("image", "common", "sharpness"); v != nil {
+		t.Fatalf("sharpness %v, want not sent", v)
+	}
+}
+
+func TestSetImageSettingsRange(t *testing.T) {
+	_, c := connect(t, true)
+	gain := 101
+	if err := c.SetImageSettings(gotapo.ImageSettings{ExpGain: &gain}); err == nil {
+		t.Fatal("exposure gain 101 accepted")
+	}
+}
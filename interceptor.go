@@ -0,0 +1,49 @@
+package gotapo
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Call is logical request to cam (before encryption).
+// Request is template or any value marshaled to JSON like
+// {"method": "set", "led": {"config": {"enabled": "on"}}}
+type Call struct {
+	Method  string
+	Request any
+	URL     string
+	Encrypt bool
+	Header  http.Header
+}
+
+// Handler send call to cam and return response in clear JSON
+type Handler func(c *Call) ([]byte, error)
+
+// Interceptor is wrapper of every request to cam. It may change call,
+// return own response without next (short-circuit), call next again (retry)
+// or change response and error
+type Interceptor func(c *Call, next Handler) ([]byte, error)
+
+// WithInterceptor add interceptors. First is outer
+func WithInterceptor(interceptors ...Interceptor) Option {
+	return func(o *Tapo) {
+		o.interceptors = append(o.interceptors, interceptors...)
+	}
+}
+
+// JSON of request with method and params
+func (c *Call) JSON() json.RawMessage {
+	b, _ := json.Marshal(c.Request)
+	return b
+}
+
+// Chain of interceptors around handler
+func chain(interceptors []Interceptor, h Handler) Handler {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], h
+		h = func(c *Call) ([]byte, error) {
+			return interceptor(c, next)
+		}
+	}
+	return h
+}
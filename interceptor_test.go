@@ -0,0 +1,106 @@
+package gotapo_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/KusoKaihatsuSha/gotapo"
+	"github.com/KusoKaihatsuSha/gotapo/gotapotest"
+)
+
+// Count of requests to fake cam with method
+func requestsOf(s *gotapotest.Server, method string) int {
+	count := 0
+	for _, v := range s.Requests() {
+		for _, m := range v.Methods {
+			if m == method {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+func TestInterceptorOrder(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	wrap := func(name string) gotapo.Interceptor {
+		return func(c *gotapo.Call, next gotapo.Handler) ([]byte, error) {
+			mu.Lock()
+			order = append(order, name+">")
+			mu.Unlock()
+			b, err := next(c)
+			mu.Lock()
+			order = append(order, "<"+name)
+			mu.Unlock()
+			return b, err
+		}
+	}
+	_, c := connect(t, true, gotapo.WithInterceptor(wrap("a"), wrap("b")))
+	if _, err := c.Info(); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if got := strings.Join(order[len(order)-4:], " "); got != "a> b> <b <a" {
+		t.Fatalf("order %q, want first is outer", got)
+	}
+}
+
+func TestInterceptorShortCircuit(t *testing.T) {
+	s, c := connect(t, true, gotapo.WithInterceptor(func(call *gotapo.Call, next gotapo.Handler) ([]byte, error) {
+		if call.Method != gotapo.MethodMR+":getDeviceInfo" {
+			return next(call)
+		}
+		return []byte(`{"error_code":0,"result":{"responses":[{"method":"getDeviceInfo","error_code":0,"result":{"device_info":{"basic_info":{"device_model":"C999"}}}}]}}`), nil
+	}))
+	info, err := c.Info()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Model != "C999" {
+		t.Fatalf("model %q, want answer of interceptor", info.Model)
+	}
+	if n := requestsOf(s, "getDeviceInfo"); n != 0 {
+		t.Fatalf("requests to cam %d, want none", n)
+	}
+}
+
+func TestInterceptorRetry(t *testing.T) {
+	s, c := connect(t, true, gotapo.WithInterceptor(func(call *gotapo.Call, next gotapo.Handler) ([]byte, error) {
+		if call.Method != gotapo.MethodMR+":getDeviceInfo" {
+			return next(call)
+		}
+		if _, err := next(call); err != nil {
+			return nil, err
+		}
+		return next(call)
+	}))
+	before := requestsOf(s, "getDeviceInfo")
+	if _, err := c.Info(); err != nil {
+		t.Fatal(err)
+	}
+	if n := requestsOf(s, "getDeviceInfo") - before; n != 2 {
+		t.Fatalf("requests to cam %d, want 2", n)
+	}
+}
+
+func TestInterceptorHeader(t *testing.T) {
+	s, c := connect(t, true, gotapo.WithInterceptor(func(call *gotapo.Call, next gotapo.Handler) ([]byte, error) {
+		call.Header.Set("X-Trace-Id", "42")
+		return next(call)
+	}))
+	if _, err := c.Info(); err != nil {
+		t.Fatal(err)
+	}
+	list := s.Requests()
+	if len(list) == 0 {
+		t.Fatal("no requests")
+	}
+	for _, v := range list {
+		if v.Header.Get("X-Trace-Id") != "42" {
+			t.Fatalf("header %v, want X-Trace-Id", v.Header)
+		}
+	}
+}
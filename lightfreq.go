@@ -0,0 +1,83 @@
+package gotapo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Modes of anti-flicker (image.common.light_freq_mode)
+const (
+	// LightFreqAuto is frequency checked by cam
+	LightFreqAuto = "auto"
+
+	// LightFreq50 is for 50 Hz lighting
+	LightFreq50 = "50"
+
+	// LightFreq60 is for 60 Hz lighting
+	LightFreq60 = "60"
+)
+
+// type light frequency return (inside multipleRequest)
+type lightFrequencyRet struct {
+	Image struct {
+		Common lightFrequencyCommon `json:"common"`
+	} `json:"image"`
+}
+
+// type light frequency values
+type lightFrequencyCommon struct {
+	LightFreqMode          string   `json:"light_freq_mode"`
+	SupportedLightFreqMode []string `json:"supported_light_freq_mode"`
+}
+
+// LightFrequency read current mode of anti-flicker and modes supported by cam.
+// Supported is nil if cam not say it (unknown)
+func (o *Tapo) LightFrequency() (string, []string, error) {
+	info, err := o.lightFrequency(lightFrequencyInfoTemplate())
+	if err != nil {
+		return "", nil, err
+	}
+	capability, err := o.lightFrequency(lightFrequencyCapabilityTemplate())
+	if errors.Is(err, ErrUnsupported) {
+		return info.LightFreqMode, nil, nil
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	if len(capability.SupportedLightFreqMode) == 0 {
+		return info.LightFreqMode, nil, nil
+	}
+	return info.LightFreqMode, capability.SupportedLightFreqMode, nil
+}
+
+// Read image.common with light frequency
+func (o *Tapo) lightFrequency(request any) (*lightFrequencyCommon, error) {
+	result, err := o.multiple(request)
+	if err != nil {
+		return nil, err
+	}
+	ret := new(lightFrequencyRet)
+	if err := json.Unmarshal(result.Result.Responses[0].Result, ret); err != nil {
+		return nil, ErrResponse
+	}
+	return &ret.Image.Common, nil
+}
+
+// SetLightFrequency write mode of anti-flicker. Mode must be supported by cam.
+// If cam not say supported modes, mode is sent as is and checked by cam
+func (o *Tapo) SetLightFrequency(mode string) error {
+	_, supported, err := o.LightFrequency()
+	if err != nil {
+		return err
+	}
+	if supported == nil {
+		return o.apply(setImageCommonTemplate(imageCommon{LightFreq: mode}))
+	}
+	for _, v := range supported {
+		if v == mode {
+			return o.apply(setImageCommonTemplate(imageCommon{LightFreq: mode}))
+		}
+	}
+	return fmt.Errorf("gotapo: light frequency %q not supported by cam %v", mode, supported)
+}
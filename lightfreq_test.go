@@ -0,0 +1,56 @@
+package gotapo_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/KusoKaihatsuSha/gotapo"
+	"github.com/KusoKaihatsuSha/gotapo/gotapotest"
+)
+
+func TestLightFrequency(t *testing.T) {
+	s, c := connect(t, true)
+	s.SetValue("image", "capability", "supported_light_freq_mode", []any{"auto", "50"})
+	mode, supported, err := c.LightFrequency()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode != gotapo.LightFreqAuto || !reflect.DeepEqual(supported, []string{"auto", "50"}) {
+		t.Fatalf("mode %q supported %v", mode, supported)
+	}
+	if err := c.SetLightFrequency(gotapo.LightFreq60); err == nil {
+		t.Fatal("not supported mode accepted")
+	}
+	if err := c.SetLightFrequency(gotapo.LightFreq50); err != nil {
+		t.Fatal(err)
+	}
+	if v := s.Value("image", "common", "light_freq_mode"); v != "50" {
+		t.Fatalf("mode %v, want 50", v)
+	}
+}
+
+func TestLightFrequencyUnknownCapability(t *testing.T) {
+	for name, prepare := range map[string]func(s *gotapotest.Server){
+		"empty":       func(s *gotapotest.Server) { s.SetValue("image", "capability", "supported_light_freq_mode", []any{}) },
+		"unsupported": func(s *gotapotest.Server) { s.Unsupported("getLightFrequencyCapability") },
+	} {
+		prepare := prepare
+		t.Run(name, func(t *testing.T) {
+			s, c := connect(t, true)
+			prepare(s)
+			mode, supported, err := c.LightFrequency()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if mode != gotapo.LightFreqAuto || supported != nil {
+				t.Fatalf("mode %q supported %v, want unknown", mode, supported)
+			}
+			if err := c.SetLightFrequency(gotapo.LightFreq60); err != nil {
+				t.Fatal(err)
+			}
+			if v := s.Value("image", "common", "light_freq_mode"); v != "60" {
+				t.Fatalf("mode %v, want 60", v)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package gotapo_test
+
+import (
+	"testing"
+
+	"github.com/KusoKaihatsuSha/gotapo"
+)
+
+func TestNightVisionWithoutWhiteLamp(t *testing.T) {
+	s, c := connect(t, true)
+	s.Unsupported("getNightVisionModeConfig", "getWhitelampConfig")
+	n, err := c.GetNightVision()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.Mode != "" || n.Intensity != 0 || n.Switch != "auto" {
+		t.Fatalf("night vision %+v, want only switch", n)
+	}
+	err = c.UpdateNightVision(func(n *gotapo.NightVision) {
+		n.Switch = gotapo.NightSwitchOn
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := s.Value("image", "common", "inf_type"); v != "on" {
+		t.Fatalf("switch %v, want on", v)
+	}
+}
+
+func TestNightVisionWhiteLamp(t *testing.T) {
+	s, c := connect(t, true)
+	err := c.UpdateNightVision(func(n *gotapo.NightVision) {
+		n.Mode = gotapo.NightVisionColor
+		n.Intensity = 70
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := c.GetNightVision()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.Mode != gotapo.NightVisionColor || n.Intensity != 70 {
+		t.Fatalf("night vision %+v, want colour with 70", n)
+	}
+	if v := s.Value("image", "switch", "wtl_intensity_level"); v != "70" {
+		t.Fatalf("intensity %v, want 70", v)
+	}
+}
+
+func TestNightVisionIntensityRange(t *testing.T) {
+	_, c := connect(t, true)
+	for _, v := range []int{-1, 101} {
+		if err := c.SetNightVision(gotapo.NightVision{Switch: gotapo.NightSwitchAuto, Intensity: v}); err == nil {
+			t.Errorf("intensity %d accepted", v)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package onvif
+
+import (
+	"context"
+	"encoding/xml"
+	"strings"
+	"time"
+)
+
+const (
+	actionCreatePullPoint = "http://www.onvif.org/ver10/events/wsdl/EventPortType/CreatePullPointSubscriptionRequest"
+	actionPullMessages    = "http://www.onvif.org/ver10/events/wsdl/PullPointSubscription/PullMessagesRequest"
+	actionUnsubscribe     = "http://docs.oasis-open.org/wsn/bw-2/SubscriptionManager/UnsubscribeRequest"
+)
+
+// Subscription is PullPoint subscription on events of cam
+type Subscription struct {
+	Address         string
+	TerminationTime time.Time
+}
+
+// Message is event of cam like motion.
+// Source and Data are SimpleItem values (like "IsMotion": "true")
+type Message struct {
+	Topic     string
+	Time      time.Time
+	Operation string
+	Source    map[string]string
+	Data      map[string]string
+}
+
+// type create subscription
+type createPullPointSubscription struct {
+	XMLName                xml.Name `xml:"http://www.onvif.org/ver10/events/wsdl CreatePullPointSubscription"`
+	InitialTerminationTime string   `xml:"InitialTerminationTime,omitempty"`
+}
+
+// type create subscription return
+type createPullPointSubscriptionResponse struct {
+	SubscriptionReference struct {
+		Address string `xml:"Address"`
+	} `xml:"SubscriptionReference"`
+	TerminationTime string `xml:"TerminationTime"`
+}
+
+// type pull messages
+type pullMessages struct {
+	XMLName      xml.Name `xml:"http://www.onvif.org/ver10/events/wsdl PullMessages"`
+	Timeout      string   `xml:"Timeout"`
+	MessageLimit int      `xml:"MessageLimit"`
+}
+
+// type simple item of message
+type simpleItem struct {
+	Name  string `xml:"Name,attr"`
+	Value string `xml:"Value,attr"`
+}
+
+// type pull messages return
+type pullMessagesResponse struct {
+	TerminationTime     string `xml:"TerminationTime"`
+	NotificationMessage []struct {
+		Topic   string `xml:"Topic"`
+		Message struct {
+			Message struct {
+				UtcTime           string       `xml:"UtcTime,attr"`
+				PropertyOperation string       `xml:"PropertyOperation,attr"`
+				Source            []simpleItem `xml:"Source>SimpleItem"`
+				Data              []simpleItem `xml:"Data>SimpleItem"`
+			} `xml:"Message"`
+		} `xml:"Message"`
+	} `xml:"NotificationMessage"`
+}
+
+// type unsubscribe
+type unsubscribe struct {
+	XMLName xml.Name `xml:"http://docs.oasis-open.org/wsn/b-2 Unsubscribe"`
+}
+
+func items(list []simpleItem) map[string]string {
+	m := map[string]string{}
+	for _, v := range list {
+		m[v.Name] = v.Value
+	}
+	return m
+}
+
+// CreatePullPointSubscription subscribe on events of cam for termination time
+func (c *Client) CreatePullPointSubscription(ctx context.Context, termination time.Duration) (*Subscription, error) {
+	url, err := c.service(ctx, "events")
+	if err != nil {
+		return nil, err
+	}
+	request := createPullPointSubscription{}
+	if termination > 0 {
+		request.InitialTerminationTime = xsdDuration(termination)
+	}
+	ret := new(createPullPointSubscriptionResponse)
+	if err := c.call(ctx, url, actionCreatePullPoint, request, ret, true); err != nil {
+		return nil, err
+	}
+	s := &Subscription{Address: strings.TrimSpace(ret.SubscriptionReference.Address)}
+	s.TerminationTime, _ = time.Parse(time.RFC3339, ret.TerminationTime)
+	if s.Address == "" {
+		s.Address = url
+	}
+	return s, nil
+}
+
+// PullMessages wait events of subscription up to timeout
+func (c *Client) PullMessages(ctx context.Context, s *Subscription, timeout time.Duration, limit int) ([]Message, error) {
+	ret := new(pullMessagesResponse)
+	if err := c.call(ctx, s.Address, actionPullMessages, pullMessages{Timeout: xsdDuration(timeout), MessageLimit: limit}, ret, true); err != nil {
+		return nil, err
+	}
+	if t, err := time.Parse(time.RFC3339, ret.TerminationTime); err == nil {
+		s.TerminationTime = t
+	}
+	messages := []Message{}
+	for _, v := range ret.NotificationMessage {
+		m := Message{
+			Topic:     strings.TrimSpace(v.Topic),
+			Operation: v.Message.Message.PropertyOperation,
+			Source:    items(v.Message.Message.Source),
+			Data:      items(v.Message.Message.Data),
+		}
+		m.Time, _ = time.Parse(time.RFC3339, v.Message.Message.UtcTime)
+		messages = append(messages, m)
+	}
+	return messages, nil
+}
+
+// Unsubscribe stop subscription
+func (c *Client) Unsubscribe(ctx context.Context, s *Subscription) error {
+	return c.call(ctx, s.Address, actionUnsubscribe, unsubscribe{}, nil, true)
+}
@@ -0,0 +1,110 @@
+package onvif
+
+import (
+	"context"
+	"encoding/xml"
+)
+
+// Profile is media profile of cam
+type Profile struct {
+	Token      string `xml:"token,attr"`
+	Name       string `xml:"Name"`
+	Resolution struct {
+		Width  int `xml:"Width"`
+		Height int `xml:"Height"`
+	} `xml:"VideoEncoderConfiguration>Resolution"`
+	Encoding string `xml:"VideoEncoderConfiguration>Encoding"`
+	PTZToken string `xml:"-"`
+}
+
+// type get profiles
+type getProfiles struct {
+	XMLName xml.Name `xml:"http://www.onvif.org/ver10/media/wsdl GetProfiles"`
+}
+
+// type get profiles return
+type getProfilesResponse struct {
+	Profiles []struct {
+		Profile
+		PTZConfiguration *struct {
+			Token string `xml:"token,attr"`
+		} `xml:"PTZConfiguration"`
+	} `xml:"Profiles"`
+}
+
+// type stream setup
+type streamSetup struct {
+	Stream    string `xml:"http://www.onvif.org/ver10/schema Stream"`
+	Transport struct {
+		Protocol string `xml:"Protocol"`
+	} `xml:"http://www.onvif.org/ver10/schema Transport"`
+}
+
+// type get stream uri
+type getStreamURI struct {
+	XMLName      xml.Name    `xml:"http://www.onvif.org/ver10/media/wsdl GetStreamUri"`
+	StreamSetup  streamSetup `xml:"StreamSetup"`
+	ProfileToken string      `xml:"ProfileToken"`
+}
+
+// type get snapshot uri
+type getSnapshotURI struct {
+	XMLName      xml.Name `xml:"http://www.onvif.org/ver10/media/wsdl GetSnapshotUri"`
+	ProfileToken string   `xml:"ProfileToken"`
+}
+
+// type get uri return
+type mediaURIResponse struct {
+	MediaURI struct {
+		URI string `xml:"Uri"`
+	} `xml:",any"`
+}
+
+// GetProfiles read media profiles of cam (main and sub stream)
+func (c *Client) GetProfiles(ctx context.Context) ([]Profile, error) {
+	url, err := c.service(ctx, "media")
+	if err != nil {
+		return nil, err
+	}
+	ret := new(getProfilesResponse)
+	if err := c.call(ctx, url, "", getProfiles{}, ret, true); err != nil {
+		return nil, err
+	}
+	profiles := []Profile{}
+	for _, v := range ret.Profiles {
+		if v.PTZConfiguration != nil {
+			v.Profile.PTZToken = v.PTZConfiguration.Token
+		}
+		profiles = append(profiles, v.Profile)
+	}
+	return profiles, nil
+}
+
+// GetStreamUri read RTSP url of profile
+func (c *Client) GetStreamUri(ctx context.Context, profileToken string) (string, error) {
+	url, err := c.service(ctx, "media")
+	if err != nil {
+		return "", err
+	}
+	request := getStreamURI{ProfileToken: profileToken}
+	request.StreamSetup.Stream = "RTP-Unicast"
+	request.StreamSetup.Transport.Protocol = "RTSP"
+	ret := new(mediaURIResponse)
+	if err := c.call(ctx, url, "", request, ret, true); err != nil {
+		return "", err
+	}
+	return ret.MediaURI.URI, nil
+}
+
+// GetSnapshotUri read url of jpeg snapshot of profile
+func (c *Client) GetSnapshotUri(ctx context.Context, profileToken string) (string, error) {
+	url, err := c.service(ctx, "media")
+	if err != nil {
+		return "", err
+	}
+	ret := new(mediaURIResponse)
+	if err := c.call(ctx, url, "", getSnapshotURI{ProfileToken: profileToken}, ret, true); err != nil {
+		return "", err
+	}
+	return ret.MediaURI.URI, nil
+}
@@ -0,0 +1,349 @@
+// Package onvif working
+// with ONVIF service of camera tapo (port 2020)
+// with the same camera account
+package onvif
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Port is default ONVIF port of cam
+	Port = "2020"
+
+	nsSoap   = "http://www.w3.org/2003/05/soap-envelope"
+	nsWsse   = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-secext-1.0.xsd"
+	nsWsu    = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-utility-1.0.xsd"
+	nsWsa    = "http://www.w3.org/2005/08/addressing"
+	nsSchema = "http://www.onvif.org/ver10/schema"
+	nsDevice = "http://www.onvif.org/ver10/device/wsdl"
+	nsMedia  = "http://www.onvif.org/ver10/media/wsdl"
+	nsPTZ    = "http://www.onvif.org/ver20/ptz/wsdl"
+	nsEvents = "http://www.onvif.org/ver10/events/wsdl"
+	nsWsnt   = "http://docs.oasis-open.org/wsn/b-2"
+
+	passwordDigest = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-username-token-profile-1.0#PasswordDigest"
+	base64Binary   = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-soap-message-security-1.0#Base64Binary"
+)
+
+// ErrNoService is returned when cam haven't service (media, ptz, events)
+var ErrNoService = errors.New("onvif: service not supported by cam")
+
+// Fault is SOAP fault returned by cam
+type Fault struct {
+	Code    string
+	Subcode string
+	Reason  string
+}
+
+func (f *Fault) Error() string {
+	return fmt.Sprintf("onvif: fault %s %s: %s", f.Code, f.Subcode, f.Reason)
+}
+
+// Client is ONVIF client of cam
+type Client struct {
+	// URL of device service, like http://192.168.1.10:2020/onvif/device_service
+	URL      string
+	User     string
+	Password string
+	HTTP     *http.Client
+
+	mu       sync.Mutex
+	services map[string]string
+	offset   time.Duration
+	synced   bool
+}
+
+// New make client of cam. Port 2020 is used if host is without port
+func New(host, user, password string) *Client {
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(host, Port)
+	}
+	return &Client{
+		URL:      "http://" + host + "/onvif/device_service",
+		User:     user,
+		Password: password,
+		HTTP:     &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// type SOAP envelope for requests
+type envelope struct {
+	XMLName xml.Name `xml:"s:Envelope"`
+	NsS     string   `xml:"xmlns:s,attr"`
+	Header  header   `xml:"s:Header"`
+	Body    struct {
+		Content any
+	} `xml:"s:Body"`
+}
+
+// type SOAP header
+type header struct {
+	Action   *wsaValue `xml:"wsa:Action,omitempty"`
+	To       *wsaValue `xml:"wsa:To,omitempty"`
+	Security *security `xml:"wsse:Security,omitempty"`
+}
+
+// type WS-Addressing value
+type wsaValue struct {
+	NsWsa string `xml:"xmlns:wsa,attr"`
+	Value string `xml:",chardata"`
+}
+
+// type WS-Security UsernameToken
+type security struct {
+	NsWsse         string `xml:"xmlns:wsse,attr"`
+	NsWsu          string `xml:"xmlns:wsu,attr"`
+	MustUnderstand string `xml:"s:mustUnderstand,attr"`
+	UsernameToken  struct {
+		Username string `xml:"wsse:Username"`
+		Password struct {
+			Type  string `xml:"Type,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"wsse:Password"`
+		Nonce struct {
+			EncodingType string `xml:"EncodingType,attr"`
+			Value        string `xml:",chardata"`
+		} `xml:"wsse:Nonce"`
+		Created string `xml:"wsu:Created"`
+	} `xml:"wsse:UsernameToken"`
+}
+
+// type SOAP envelope for responses
+type responseEnvelope struct {
+	Body struct {
+		Fault *struct {
+			Code struct {
+				Value   string `xml:"Value"`
+				Subcode struct {
+					Value string `xml:"Value"`
+				} `xml:"Subcode"`
+			} `xml:"Code"`
+			Reason struct {
+				Text string `xml:"Text"`
+			} `xml:"Reason"`
+		} `xml:"Fault"`
+		Content []byte `xml:",innerxml"`
+	} `xml:"Body"`
+}
+
+// Make UsernameToken with digest = base64(sha1(nonce + created + password))
+func (c *Client) token(now time.Time) *security {
+	nonce := make([]byte, 16)
+	rand.Read(nonce)
+	created := now.UTC().Format("2006-01-02T15:04:05.000Z")
+	h := sha1.New()
+	h.Write(nonce)
+	h.Write([]byte(created))
+	h.Write([]byte(c.Password))
+	s := &security{NsWsse: nsWsse, NsWsu: nsWsu, MustUnderstand: "1"}
+	s.UsernameToken.Username = c.User
+	s.UsernameToken.Password.Type = passwordDigest
+	s.UsernameToken.Password.Value = base64.StdEncoding.EncodeToString(h.Sum(nil))
+	s.UsernameToken.Nonce.EncodingType = base64Binary
+	s.UsernameToken.Nonce.Value = base64.StdEncoding.EncodeToString(nonce)
+	s.UsernameToken.Created = created
+	return s
+}
+
+// call send request to url of service and decode body of response
+func (c *Client) call(ctx context.Context, url, action string, request, response any, auth bool) error {
+	env := envelope{NsS: nsSoap}
+	env.Body.Content = request
+	if auth {
+		c.syncTime(ctx)
+		c.mu.Lock()
+		now := time.Now().Add(c.offset)
+		c.mu.Unlock()
+		env.Header.Security = c.token(now)
+	}
+	if action != "" {
+		env.Header.Action = &wsaValue{NsWsa: nsWsa, Value: action}
+		env.Header.To = &wsaValue{NsWsa: nsWsa, Value: url}
+	}
+	body, err := xml.Marshal(env)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(append([]byte(xml.Header), body...)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/soap+xml; charset=utf-8")
+	client := c.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	ret := new(responseEnvelope)
+	if err := xml.Unmarshal(b, ret); err != nil {
+		return fmt.Errorf("onvif: bad response (http %d): %w", resp.StatusCode, err)
+	}
+	if f := ret.Body.Fault; f != nil {
+		return &Fault{Code: f.Code.Value, Subcode: f.Code.Subcode.Value, Reason: strings.TrimSpace(f.Reason.Text)}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("onvif: http status %d", resp.StatusCode)
+	}
+	if response == nil {
+		return nil
+	}
+	return xml.Unmarshal(ret.Body.Content, response)
+}
+
+// type get time of cam (without auth)
+type getSystemDateAndTime struct {
+	XMLName xml.Name `xml:"http://www.onvif.org/ver10/device/wsdl GetSystemDateAndTime"`
+}
+
+// type get time of cam return
+type getSystemDateAndTimeResponse struct {
+	SystemDateAndTime struct {
+		UTCDateTime struct {
+			Date struct {
+				Year  int `xml:"Year"`
+				Month int `xml:"Month"`
+				Day   int `xml:"Day"`
+			} `xml:"Date"`
+			Time struct {
+				Hour   int `xml:"Hour"`
+				Minute int `xml:"Minute"`
+				Second int `xml:"Second"`
+			} `xml:"Time"`
+		} `xml:"UTCDateTime"`
+	} `xml:"SystemDateAndTime"`
+}
+
+// Difference of clock of cam. Created of token must be in time of cam.
+// Synced only after answer of cam, on error it is tried on next request
+func (c *Client) syncTime(ctx context.Context) {
+	c.mu.Lock()
+	synced := c.synced
+	c.mu.Unlock()
+	if synced {
+		return
+	}
+	ret := new(getSystemDateAndTimeResponse)
+	if err := c.call(ctx, c.URL, "", getSystemDateAndTime{}, ret, false); err != nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.synced = true
+	d := ret.SystemDateAndTime.UTCDateTime
+	if d.Date.Year == 0 {
+		return
+	}
+	cam := time.Date(d.Date.Year, time.Month(d.Date.Month), d.Date.Day, d.Time.Hour, d.Time.Minute, d.Time.Second, 0, time.UTC)
+	c.offset = time.Until(cam)
+}
+
+// type get capabilities
+type getCapabilities struct {
+	XMLName  xml.Name `xml:"http://www.onvif.org/ver10/device/wsdl GetCapabilities"`
+	Category string   `xml:"Category"`
+}
+
+// type get capabilities return
+type getCapabilitiesResponse struct {
+	Capabilities struct {
+		Device struct {
+			XAddr string `xml:"XAddr"`
+		} `xml:"Device"`
+		Events struct {
+			XAddr string `xml:"XAddr"`
+		} `xml:"Events"`
+		Media struct {
+			XAddr string `xml:"XAddr"`
+		} `xml:"Media"`
+		PTZ struct {
+			XAddr string `xml:"XAddr"`
+		} `xml:"PTZ"`
+	} `xml:"Capabilities"`
+}
+
+// URL of service (media, ptz, events). Read once from capabilities of cam
+func (c *Client) service(ctx context.Context, name string) (string, error) {
+	c.mu.Lock()
+	services := c.services
+	c.mu.Unlock()
+	if services == nil {
+		ret := new(getCapabilitiesResponse)
+		if err := c.call(ctx, c.URL, "", getCapabilities{Category: "All"}, ret, true); err != nil {
+			return "", err
+		}
+		services = map[string]string{
+			"device": ret.Capabilities.Device.XAddr,
+			"media":  ret.Capabilities.Media.XAddr,
+			"ptz":    ret.Capabilities.PTZ.XAddr,
+			"events": ret.Capabilities.Events.XAddr,
+		}
+		c.mu.Lock()
+		c.services = services
+		c.mu.Unlock()
+	}
+	url := services[name]
+	if url == "" {
+		return "", ErrNoService
+	}
+	return url, nil
+}
+
+// DeviceInformation is info about cam
+type DeviceInformation struct {
+	Manufacturer    string `xml:"Manufacturer"`
+	Model           string `xml:"Model"`
+	FirmwareVersion string `xml:"FirmwareVersion"`
+	SerialNumber    string `xml:"SerialNumber"`
+	HardwareID      string `xml:"HardwareId"`
+}
+
+// type get device information
+type getDeviceInformation struct {
+	XMLName xml.Name `xml:"http://www.onvif.org/ver10/device/wsdl GetDeviceInformation"`
+}
+
+// type get device information return
+type getDeviceInformationResponse struct {
+	DeviceInformation
+}
+
+// GetDeviceInformation read manufacturer, model, firmware of cam
+func (c *Client) GetDeviceInformation(ctx context.Context) (DeviceInformation, error) {
+	ret := new(getDeviceInformationResponse)
+	if err := c.call(ctx, c.URL, "", getDeviceInformation{}, ret, true); err != nil {
+		return DeviceInformation{}, err
+	}
+	return ret.DeviceInformation, nil
+}
+
+// type reboot
+type systemReboot struct {
+	XMLName xml.Name `xml:"http://www.onvif.org/ver10/device/wsdl SystemReboot"`
+}
+
+// SystemReboot reboot cam
+func (c *Client) SystemReboot(ctx context.Context) error {
+	return c.call(ctx, c.URL, "", systemReboot{}, nil, true)
+}
@@ -0,0 +1,241 @@
+package onvif_test
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KusoKaihatsuSha/gotapo/onvif"
+	"github.com/KusoKaihatsuSha/gotapo/onvif/onviftest"
+)
+
+func connect(t *testing.T) (*onviftest.Server, *onvif.Client) {
+	t.Helper()
+	s := onviftest.NewServer("cam", "secret")
+	t.Cleanup(s.Close)
+	return s, onvif.New(s.Host, "cam", "secret")
+}
+
+func TestEnvelope(t *testing.T) {
+	var body []byte
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		if strings.Contains(string(b), "GetDeviceInformation") {
+			body = b
+		}
+		if ct := r.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/soap+xml") {
+			t.Errorf("content type %q", ct)
+		}
+		io.WriteString(w, `<Envelope xmlns="http://www.w3.org/2003/05/soap-envelope"><Body>`+
+			`<GetDeviceInformationResponse><Model>C200</Model></GetDeviceInformationResponse></Body></Envelope>`)
+	}))
+	defer s.Close()
+	c := onvif.New(strings.TrimPrefix(s.URL, "http://"), "cam", "secret")
+	info, err := c.GetDeviceInformation(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Model != "C200" {
+		t.Fatalf("model %q, want C200", info.Model)
+	}
+	env := struct {
+		XMLName xml.Name
+		Header  struct {
+			Security struct {
+				XMLName        xml.Name
+				MustUnderstand string `xml:"mustUnderstand,attr"`
+				UsernameToken  struct {
+					Username string `xml:"Username"`
+					Password struct {
+						Type string `xml:"Type,attr"`
+					} `xml:"Password"`
+					Nonce   string `xml:"Nonce"`
+					Created string `xml:"Created"`
+				} `xml:"UsernameToken"`
+			} `xml:"Security"`
+		} `xml:"Header"`
+		Body struct {
+			Content struct {
+				XMLName xml.Name
+			} `xml:",any"`
+		} `xml:"Body"`
+	}{}
+	if err := xml.Unmarshal(body, &env); err != nil {
+		t.Fatal(err)
+	}
+	if env.XMLName.Space != "http://www.w3.org/2003/05/soap-envelope" || env.XMLName.Local != "Envelope" {
+		t.Fatalf("envelope %v", env.XMLName)
+	}
+	security := env.Header.Security
+	if !strings.HasSuffix(security.XMLName.Space, "wssecurity-secext-1.0.xsd") || security.MustUnderstand != "1" {
+		t.Fatalf("security %v mustUnderstand %q", security.XMLName, security.MustUnderstand)
+	}
+	token := security.UsernameToken
+	if token.Username != "cam" || !strings.HasSuffix(token.Password.Type, "#PasswordDigest") || token.Nonce == "" {
+		t.Fatalf("token %+v", token)
+	}
+	if _, err := time.Parse("2006-01-02T15:04:05.000Z", token.Created); err != nil {
+		t.Fatalf("created %q: %v", token.Created, err)
+	}
+	if op := env.Body.Content.XMLName; op.Space != "http://www.onvif.org/ver10/device/wsdl" || op.Local != "GetDeviceInformation" {
+		t.Fatalf("operation %v", op)
+	}
+	if strings.Contains(string(body), "secret") {
+		t.Fatal("password in clear text")
+	}
+}
+
+func TestDigestWithClockOfCam(t *testing.T) {
+	s, c := connect(t)
+	s.Offset = 2 * time.Hour
+	info, err := c.GetDeviceInformation(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Model != "C200" || info.SerialNumber != "0000001" {
+		t.Fatalf("info %+v", info)
+	}
+}
+
+func TestWrongPassword(t *testing.T) {
+	s, _ := connect(t)
+	c := onvif.New(s.Host, "cam", "wrong")
+	_, err := c.GetDeviceInformation(context.Background())
+	fault := new(onvif.Fault)
+	if !errors.As(err, &fault) || fault.Subcode != "ter:NotAuthorized" {
+		t.Fatalf("error %v, want not authorized", err)
+	}
+}
+
+func TestSyncTimeRetry(t *testing.T) {
+	s, c := connect(t)
+	s.Offset = 2 * time.Hour
+	s.FailTime(1)
+	if _, err := c.GetDeviceInformation(context.Background()); err == nil {
+		t.Fatal("token accepted without time of cam")
+	}
+	if _, err := c.GetDeviceInformation(context.Background()); err != nil {
+		t.Fatalf("time of cam not synced again: %v", err)
+	}
+}
+
+func TestPTZ(t *testing.T) {
+	s, c := connect(t)
+	s.SetPresets(onvif.Preset{Token: "1", Name: "Door & hall"}, onvif.Preset{Token: "7", Name: "Garden"})
+	ctx := context.Background()
+	profiles, err := c.GetProfiles(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profiles) != 2 || profiles[0].Token != "profile_1" || profiles[0].PTZToken == "" || profiles[1].PTZToken != "" {
+		t.Fatalf("profiles %+v", profiles)
+	}
+	if p := profiles[0]; p.Resolution.Width != 1920 || p.Resolution.Height != 1080 || p.Encoding != "H264" {
+		t.Fatalf("profile %+v", p)
+	}
+	presets, err := c.GetPresets(ctx, profiles[0].Token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []onvif.Preset{{Token: "1", Name: "Door & hall"}, {Token: "7", Name: "Garden"}}
+	if !reflect.DeepEqual(presets, want) {
+		t.Fatalf("presets %+v, want %+v", presets, want)
+	}
+	if err := c.GotoPreset(ctx, profiles[0].Token, "7"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.GotoPreset(ctx, profiles[0].Token, "3"); err == nil {
+		t.Fatal("goto to unknown preset")
+	}
+	if gotos := s.Gotos(); !reflect.DeepEqual(gotos, []string{"7"}) {
+		t.Fatalf("gotos %v, want [7]", gotos)
+	}
+}
+
+func TestMediaURI(t *testing.T) {
+	s, c := connect(t)
+	ctx := context.Background()
+	host := strings.Split(s.Host, ":")[0]
+	for profile, want := range map[string]string{"profile_1": "rtsp://" + host + ":554/stream1", "profile_2": "rtsp://" + host + ":554/stream2"} {
+		uri, err := c.GetStreamUri(ctx, profile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if uri != want {
+			t.Fatalf("stream uri %q, want %q", uri, want)
+		}
+	}
+	uri, err := c.GetSnapshotUri(ctx, "profile_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "http://" + s.Host + "/snapshot/stream1.jpg"; uri != want {
+		t.Fatalf("snapshot uri %q, want %q", uri, want)
+	}
+	fault := new(onvif.Fault)
+	if _, err := c.GetStreamUri(ctx, "profile_9"); !errors.As(err, &fault) || fault.Subcode != "ter:NoProfile" {
+		t.Fatalf("error %v, want no profile", err)
+	}
+}
+
+func TestPullPoint(t *testing.T) {
+	s, c := connect(t)
+	ctx := context.Background()
+	sub, err := c.CreatePullPointSubscription(ctx, 2*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(sub.Address, "http://"+s.Host+"/onvif/subscription/") {
+		t.Fatalf("address %q", sub.Address)
+	}
+	if d := time.Until(sub.TerminationTime); d < time.Minute || d > 2*time.Minute+time.Second {
+		t.Fatalf("termination %v, want in 2 minutes", sub.TerminationTime)
+	}
+	messages, err := c.PullMessages(ctx, sub, time.Second, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 0 {
+		t.Fatalf("messages %+v, want none", messages)
+	}
+	at := time.Now().UTC().Truncate(time.Second)
+	motion := onvif.Message{
+		Topic:     "tns1:RuleEngine/CellMotionDetector/Motion",
+		Time:      at,
+		Operation: "Changed",
+		Source:    map[string]string{"VideoSourceConfigurationToken": "vsconf", "Rule": "MyMotionDetectorRule"},
+		Data:      map[string]string{"IsMotion": "true"},
+	}
+	s.Notify(motion)
+	second := motion
+	second.Data = map[string]string{"IsMotion": "false"}
+	s.Notify(second)
+	if messages, err = c.PullMessages(ctx, sub, time.Second, 1); err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 1 || !reflect.DeepEqual(messages[0], motion) {
+		t.Fatalf("messages %+v, want %+v", messages, motion)
+	}
+	if messages, err = c.PullMessages(ctx, sub, time.Second, 10); err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 1 || messages[0].Data["IsMotion"] != "false" {
+		t.Fatalf("messages %+v, want end of motion", messages)
+	}
+	if err := c.Unsubscribe(ctx, sub); err != nil {
+		t.Fatal(err)
+	}
+	if s.Subscriptions() != 0 {
+		t.Fatalf("subscriptions %d after unsubscribe", s.Subscriptions())
+	}
+	if _, err := c.PullMessages(ctx, sub, time.Second, 10); err == nil {
+		t.Fatal("pull after unsubscribe")
+	}
+}
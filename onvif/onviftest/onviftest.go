@@ -0,0 +1,370 @@
+// Package onviftest is fake ONVIF service of cam tapo for tests.
+// It check WS-Security UsernameToken (digest and time of cam) and answer
+// device, media, ptz and events (PullPoint) requests
+package onviftest
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/KusoKaihatsuSha/gotapo/onvif"
+)
+
+const (
+	nsSoap   = "http://www.w3.org/2003/05/soap-envelope"
+	nsSchema = "http://www.onvif.org/ver10/schema"
+	nsDevice = "http://www.onvif.org/ver10/device/wsdl"
+	nsMedia  = "http://www.onvif.org/ver10/media/wsdl"
+	nsPTZ    = "http://www.onvif.org/ver20/ptz/wsdl"
+	nsEvents = "http://www.onvif.org/ver10/events/wsdl"
+	nsWsnt   = "http://docs.oasis-open.org/wsn/b-2"
+	nsWsa    = "http://www.w3.org/2005/08/addressing"
+
+	actionCreatePullPoint = "http://www.onvif.org/ver10/events/wsdl/EventPortType/CreatePullPointSubscriptionRequest"
+	actionPullMessages    = "http://www.onvif.org/ver10/events/wsdl/PullPointSubscription/PullMessagesRequest"
+	actionUnsubscribe     = "http://docs.oasis-open.org/wsn/bw-2/SubscriptionManager/UnsubscribeRequest"
+
+	subscriptionPath = "/onvif/subscription/"
+
+	passwordDigest = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-username-token-profile-1.0#PasswordDigest"
+)
+
+// Server is fake ONVIF service. Offset is difference of clock of cam,
+// Created of token must be in time of cam (± Skew)
+type Server struct {
+	*httptest.Server
+	Host     string
+	User     string
+	Password string
+	Offset   time.Duration
+	Skew     time.Duration
+
+	mu            sync.Mutex
+	presets       []onvif.Preset
+	calls         []string
+	gotos         []string
+	failTime      int
+	subscriptions map[string]*subscription
+	subscribed    int
+}
+
+// PullPoint subscription with messages not pulled yet
+type subscription struct {
+	termination time.Time
+	messages    []onvif.Message
+}
+
+// type request of client
+type request struct {
+	XMLName xml.Name
+	Header  struct {
+		Action   string `xml:"Action"`
+		Security *struct {
+			UsernameToken struct {
+				Username string `xml:"Username"`
+				Password struct {
+					Type  string `xml:"Type,attr"`
+					Value string `xml:",chardata"`
+				} `xml:"Password"`
+				Nonce   string `xml:"Nonce"`
+				Created string `xml:"Created"`
+			} `xml:"UsernameToken"`
+		} `xml:"Security"`
+	} `xml:"Header"`
+	Body struct {
+		Content struct {
+			XMLName      xml.Name
+			ProfileToken string `xml:"ProfileToken"`
+			PresetToken  string `xml:"PresetToken"`
+			StreamSetup  struct {
+				Stream    string `xml:"Stream"`
+				Transport struct {
+					Protocol string `xml:"Protocol"`
+				} `xml:"Transport"`
+			} `xml:"StreamSetup"`
+			InitialTerminationTime string `xml:"InitialTerminationTime"`
+			MessageLimit           int    `xml:"MessageLimit"`
+		} `xml:",any"`
+	} `xml:"Body"`
+}
+
+// NewServer start fake ONVIF service with two presets. Close it after usage
+func NewServer(user, password string) *Server {
+	s := &Server{
+		User:          user,
+		Password:      password,
+		Skew:          time.Minute,
+		subscriptions: map[string]*subscription{},
+		presets: []onvif.Preset{
+			{Token: "1", Name: "Door"},
+			{Token: "2", Name: "Window"},
+		},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	s.Host = strings.TrimPrefix(s.Server.URL, "http://")
+	return s
+}
+
+// SetPresets replace PTZ presets of fake cam
+func (s *Server) SetPresets(presets ...onvif.Preset) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.presets = append([]onvif.Preset{}, presets...)
+}
+
+// FailTime answer next count requests of time with fault
+func (s *Server) FailTime(count int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failTime += count
+}
+
+// Calls is names of operations accepted by fake cam (auth is passed)
+func (s *Server) Calls() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.calls...)
+}
+
+// Gotos is tokens of presets from GotoPreset
+func (s *Server) Gotos() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.gotos...)
+}
+
+// Notify add message to all PullPoint subscriptions
+func (s *Server) Notify(m onvif.Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, v := range s.subscriptions {
+		v.messages = append(v.messages, m)
+	}
+}
+
+// Subscriptions is count of PullPoint subscriptions not unsubscribed
+func (s *Server) Subscriptions() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.subscriptions)
+}
+
+// Time of clock of fake cam
+func (s *Server) now() time.Time {
+	return time.Now().Add(s.Offset)
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	body, _ := io.ReadAll(r.Body)
+	req := new(request)
+	if err := xml.Unmarshal(body, req); err != nil || req.XMLName.Space != nsSoap || req.XMLName.Local != "Envelope" {
+		fault(w, "env:Sender", "ter:WellFormed", "bad envelope")
+		return
+	}
+	op := req.Body.Content.XMLName
+	if op.Local == "GetSystemDateAndTime" {
+		if s.failTime > 0 {
+			s.failTime--
+			fault(w, "env:Receiver", "ter:Action", "clock not ready")
+			return
+		}
+		t := s.now().UTC()
+		write(w, fmt.Sprintf(`<tds:GetSystemDateAndTimeResponse xmlns:tds=%q xmlns:tt=%q><tds:SystemDateAndTime>`+
+			`<tt:DateTimeType>NTP</tt:DateTimeType><tt:UTCDateTime>`+
+			`<tt:Date><tt:Year>%d</tt:Year><tt:Month>%d</tt:Month><tt:Day>%d</tt:Day></tt:Date>`+
+			`<tt:Time><tt:Hour>%d</tt:Hour><tt:Minute>%d</tt:Minute><tt:Second>%d</tt:Second></tt:Time>`+
+			`</tt:UTCDateTime></tds:SystemDateAndTime></tds:GetSystemDateAndTimeResponse>`,
+			nsDevice, nsSchema, t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second()))
+		return
+	}
+	if reason := s.auth(req); reason != "" {
+		fault(w, "env:Sender", "ter:NotAuthorized", reason)
+		return
+	}
+	s.calls = append(s.calls, op.Local)
+	switch {
+	case op.Space == nsDevice && op.Local == "GetCapabilities":
+		write(w, fmt.Sprintf(`<tds:GetCapabilitiesResponse xmlns:tds=%q xmlns:tt=%q><tds:Capabilities>`+
+			`<tt:Device><tt:XAddr>%[3]s/onvif/device_service</tt:XAddr></tt:Device>`+
+			`<tt:Events><tt:XAddr>%[3]s/onvif/service</tt:XAddr></tt:Events>`+
+			`<tt:Media><tt:XAddr>%[3]s/onvif/service</tt:XAddr></tt:Media>`+
+			`<tt:PTZ><tt:XAddr>%[3]s/onvif/service</tt:XAddr></tt:PTZ>`+
+			`</tds:Capabilities></tds:GetCapabilitiesResponse>`, nsDevice, nsSchema, s.URL))
+	case op.Space == nsDevice && op.Local == "GetDeviceInformation":
+		write(w, fmt.Sprintf(`<tds:GetDeviceInformationResponse xmlns:tds=%q>`+
+			`<tds:Manufacturer>TP-Link</tds:Manufacturer><tds:Model>C200</tds:Model>`+
+			`<tds:FirmwareVersion>1.3.9</tds:FirmwareVersion><tds:SerialNumber>0000001</tds:SerialNumber>`+
+			`<tds:HardwareId>2.0</tds:HardwareId></tds:GetDeviceInformationResponse>`, nsDevice))
+	case op.Space == nsDevice && op.Local == "SystemReboot":
+		write(w, fmt.Sprintf(`<tds:SystemRebootResponse xmlns:tds=%q><tds:Message>Rebooting</tds:Message></tds:SystemRebootResponse>`, nsDevice))
+	case op.Space == nsMedia && op.Local == "GetProfiles":
+		write(w, fmt.Sprintf(`<trt:GetProfilesResponse xmlns:trt=%q xmlns:tt=%q>`+
+			`<trt:Profiles token="profile_1"><tt:Name>mainStream</tt:Name><tt:VideoEncoderConfiguration>`+
+			`<tt:Encoding>H264</tt:Encoding><tt:Resolution><tt:Width>1920</tt:Width><tt:Height>1080</tt:Height></tt:Resolution>`+
+			`</tt:VideoEncoderConfiguration><tt:PTZConfiguration token="PTZConfiguration_1"></tt:PTZConfiguration></trt:Profiles>`+
+			`<trt:Profiles token="profile_2"><tt:Name>minorStream</tt:Name><tt:VideoEncoderConfiguration>`+
+			`<tt:Encoding>H264</tt:Encoding><tt:Resolution><tt:Width>640</tt:Width><tt:Height>360</tt:Height></tt:Resolution>`+
+			`</tt:VideoEncoderConfiguration></trt:Profiles></trt:GetProfilesResponse>`, nsMedia, nsSchema))
+	case op.Space == nsMedia && (op.Local == "GetStreamUri" || op.Local == "GetSnapshotUri"):
+		stream := map[string]string{"profile_1": "stream1", "profile_2": "stream2"}[req.Body.Content.ProfileToken]
+		if stream == "" {
+			fault(w, "env:Sender", "ter:NoProfile", "profile not found")
+			return
+		}
+		host := strings.Split(s.Host, ":")[0]
+		uri := "rtsp://" + host + ":554/" + stream
+		if op.Local == "GetSnapshotUri" {
+			uri = "http://" + s.Host + "/snapshot/" + stream + ".jpg"
+		} else if setup := req.Body.Content.StreamSetup; setup.Stream != "RTP-Unicast" || setup.Transport.Protocol != "RTSP" {
+			fault(w, "env:Sender", "ter:InvalidStreamSetup", "only RTP-Unicast over RTSP")
+			return
+		}
+		write(w, fmt.Sprintf(`<trt:%sResponse xmlns:trt=%q xmlns:tt=%q><trt:MediaUri><tt:Uri>%s</tt:Uri>`+
+			`<tt:InvalidAfterConnect>false</tt:InvalidAfterConnect><tt:InvalidAfterReboot>false</tt:InvalidAfterReboot>`+
+			`<tt:Timeout>PT0S</tt:Timeout></trt:MediaUri></trt:%[1]sResponse>`, op.Local, nsMedia, nsSchema, html.EscapeString(uri)))
+	case op.Space == nsEvents && op.Local == "CreatePullPointSubscription":
+		if req.Header.Action != actionCreatePullPoint {
+			fault(w, "env:Sender", "wsa:ActionNotSupported", "bad action")
+			return
+		}
+		termination := time.Minute
+		if v := req.Body.Content.InitialTerminationTime; v != "" {
+			sec, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimPrefix(v, "PT"), "S"), 64)
+			if err != nil {
+				fault(w, "env:Sender", "ter:InvalidArgVal", "bad termination time")
+				return
+			}
+			termination = time.Duration(sec * float64(time.Second))
+		}
+		s.subscribed++
+		id := strconv.Itoa(s.subscribed)
+		sub := &subscription{termination: s.now().Add(termination).UTC()}
+		s.subscriptions[id] = sub
+		write(w, fmt.Sprintf(`<tev:CreatePullPointSubscriptionResponse xmlns:tev=%q xmlns:wsnt=%q xmlns:wsa5=%q>`+
+			`<tev:SubscriptionReference><wsa5:Address>%s%s%s</wsa5:Address></tev:SubscriptionReference>`+
+			`<wsnt:CurrentTime>%s</wsnt:CurrentTime><wsnt:TerminationTime>%s</wsnt:TerminationTime>`+
+			`</tev:CreatePullPointSubscriptionResponse>`, nsEvents, nsWsnt, nsWsa, s.URL, subscriptionPath, id,
+			s.now().UTC().Format(time.RFC3339), sub.termination.Format(time.RFC3339)))
+	case op.Space == nsEvents && op.Local == "PullMessages":
+		sub := s.subscriptions[strings.TrimPrefix(r.URL.Path, subscriptionPath)]
+		if sub == nil || req.Header.Action != actionPullMessages {
+			fault(w, "env:Sender", "ter:InvalidArgVal", "subscription not found")
+			return
+		}
+		n := len(sub.messages)
+		if limit := req.Body.Content.MessageLimit; limit > 0 && limit < n {
+			n = limit
+		}
+		list := ""
+		for _, m := range sub.messages[:n] {
+			list += notification(m)
+		}
+		sub.messages = sub.messages[n:]
+		write(w, fmt.Sprintf(`<tev:PullMessagesResponse xmlns:tev=%q xmlns:wsnt=%q xmlns:tt=%q>`+
+			`<tev:CurrentTime>%s</tev:CurrentTime><tev:TerminationTime>%s</tev:TerminationTime>%s</tev:PullMessagesResponse>`,
+			nsEvents, nsWsnt, nsSchema, s.now().UTC().Format(time.RFC3339), sub.termination.Format(time.RFC3339), list))
+	case op.Space == nsWsnt && op.Local == "Unsubscribe":
+		id := strings.TrimPrefix(r.URL.Path, subscriptionPath)
+		if s.subscriptions[id] == nil || req.Header.Action != actionUnsubscribe {
+			fault(w, "env:Sender", "ter:InvalidArgVal", "subscription not found")
+			return
+		}
+		delete(s.subscriptions, id)
+		write(w, fmt.Sprintf(`<wsnt:UnsubscribeResponse xmlns:wsnt=%q></wsnt:UnsubscribeResponse>`, nsWsnt))
+	case op.Space == nsPTZ && op.Local == "GetPresets":
+		list := ""
+		for _, v := range s.presets {
+			list += fmt.Sprintf(`<tptz:Preset token=%q><tt:Name>%s</tt:Name></tptz:Preset>`, v.Token, html.EscapeString(v.Name))
+		}
+		write(w, fmt.Sprintf(`<tptz:GetPresetsResponse xmlns:tptz=%q xmlns:tt=%q>%s</tptz:GetPresetsResponse>`, nsPTZ, nsSchema, list))
+	case op.Space == nsPTZ && op.Local == "GotoPreset":
+		for _, v := range s.presets {
+			if v.Token == req.Body.Content.PresetToken {
+				s.gotos = append(s.gotos, v.Token)
+				write(w, fmt.Sprintf(`<tptz:GotoPresetResponse xmlns:tptz=%q></tptz:GotoPresetResponse>`, nsPTZ))
+				return
+			}
+		}
+		fault(w, "env:Sender", "ter:NoToken", "preset not found")
+	case op.Space == nsPTZ && (op.Local == "ContinuousMove" || op.Local == "Stop"):
+		write(w, fmt.Sprintf(`<tptz:%sResponse xmlns:tptz=%q></tptz:%[1]sResponse>`, op.Local, nsPTZ))
+	default:
+		fault(w, "env:Receiver", "ter:ActionNotSupported", "action not supported")
+	}
+}
+
+// Message of PullMessages answer
+func notification(m onvif.Message) string {
+	items := func(values map[string]string) string {
+		names := []string{}
+		for k := range values {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		list := ""
+		for _, k := range names {
+			list += fmt.Sprintf(`<tt:SimpleItem Name="%s" Value="%s"/>`, html.EscapeString(k), html.EscapeString(values[k]))
+		}
+		return list
+	}
+	return fmt.Sprintf(`<wsnt:NotificationMessage><wsnt:Topic Dialect="http://www.onvif.org/ver10/tev/topicExpression/ConcreteSet">%s</wsnt:Topic>`+
+		`<wsnt:Message><tt:Message UtcTime="%s" PropertyOperation="%s"><tt:Source>%s</tt:Source><tt:Data>%s</tt:Data></tt:Message></wsnt:Message>`+
+		`</wsnt:NotificationMessage>`, html.EscapeString(m.Topic), m.Time.UTC().Format(time.RFC3339), html.EscapeString(m.Operation), items(m.Source), items(m.Data))
+}
+
+// Check UsernameToken: user, digest = base64(sha1(nonce + created + password))
+// and created in time of cam. Empty - passed
+func (s *Server) auth(req *request) string {
+	if req.Header.Security == nil {
+		return "without security"
+	}
+	token := req.Header.Security.UsernameToken
+	if token.Username != s.User || token.Password.Type != passwordDigest {
+		return "bad user"
+	}
+	nonce, err := base64.StdEncoding.DecodeString(token.Nonce)
+	if err != nil {
+		return "bad nonce"
+	}
+	h := sha1.New()
+	h.Write(nonce)
+	h.Write([]byte(token.Created))
+	h.Write([]byte(s.Password))
+	if base64.StdEncoding.EncodeToString(h.Sum(nil)) != token.Password.Value {
+		return "bad digest"
+	}
+	created, err := time.Parse(time.RFC3339, token.Created)
+	if err != nil {
+		return "bad created"
+	}
+	if d := created.Sub(s.now()); d > s.Skew || d < -s.Skew {
+		return "created out of time of cam"
+	}
+	return ""
+}
+
+func write(w http.ResponseWriter, content string) {
+	w.Header().Set("Content-Type", "application/soap+xml; charset=utf-8")
+	fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?><env:Envelope xmlns:env=%q><env:Body>%s</env:Body></env:Envelope>`, nsSoap, content)
+}
+
+func fault(w http.ResponseWriter, code, subcode, reason string) {
+	w.Header().Set("Content-Type", "application/soap+xml; charset=utf-8")
+	w.WriteHeader(http.StatusBadRequest)
+	fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?><env:Envelope xmlns:env=%q xmlns:ter="http://www.onvif.org/ver10/error"><env:Body>`+
+		`<env:Fault><env:Code><env:Value>%s</env:Value><env:Subcode><env:Value>%s</env:Value></env:Subcode></env:Code>`+
+		`<env:Reason><env:Text xml:lang="en">%s</env:Text></env:Reason></env:Fault></env:Body></env:Envelope>`, nsSoap, code, subcode, reason)
+}
@@ -0,0 +1,122 @@
+package onvif
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"time"
+)
+
+// Velocity of PTZ move, values -1..1
+type Velocity struct {
+	Pan  float64
+	Tilt float64
+	Zoom float64
+}
+
+// Preset is PTZ preset of cam
+type Preset struct {
+	Token string `xml:"token,attr"`
+	Name  string `xml:"Name"`
+}
+
+// type vector of pan/tilt
+type vector2D struct {
+	X float64 `xml:"x,attr"`
+	Y float64 `xml:"y,attr"`
+}
+
+// type vector of zoom
+type vector1D struct {
+	X float64 `xml:"x,attr"`
+}
+
+// type continuous move
+type continuousMove struct {
+	XMLName      xml.Name `xml:"http://www.onvif.org/ver20/ptz/wsdl ContinuousMove"`
+	ProfileToken string   `xml:"ProfileToken"`
+	Velocity     struct {
+		PanTilt *vector2D `xml:"http://www.onvif.org/ver10/schema PanTilt,omitempty"`
+		Zoom    *vector1D `xml:"http://www.onvif.org/ver10/schema Zoom,omitempty"`
+	} `xml:"Velocity"`
+	Timeout string `xml:"Timeout,omitempty"`
+}
+
+// type stop move
+type stop struct {
+	XMLName      xml.Name `xml:"http://www.onvif.org/ver20/ptz/wsdl Stop"`
+	ProfileToken string   `xml:"ProfileToken"`
+	PanTilt      bool     `xml:"PanTilt"`
+	Zoom         bool     `xml:"Zoom"`
+}
+
+// type goto preset
+type gotoPreset struct {
+	XMLName      xml.Name `xml:"http://www.onvif.org/ver20/ptz/wsdl GotoPreset"`
+	ProfileToken string   `xml:"ProfileToken"`
+	PresetToken  string   `xml:"PresetToken"`
+}
+
+// type get presets
+type getPresets struct {
+	XMLName      xml.Name `xml:"http://www.onvif.org/ver20/ptz/wsdl GetPresets"`
+	ProfileToken string   `xml:"ProfileToken"`
+}
+
+// type get presets return
+type getPresetsResponse struct {
+	Preset []Preset `xml:"Preset"`
+}
+
+// Duration in xsd format like "PT1.5S"
+func xsdDuration(d time.Duration) string {
+	return fmt.Sprintf("PT%gS", d.Seconds())
+}
+
+// ContinuousMove start moving with velocity. Zero timeout - move until Stop
+func (c *Client) ContinuousMove(ctx context.Context, profileToken string, v Velocity, timeout time.Duration) error {
+	url, err := c.service(ctx, "ptz")
+	if err != nil {
+		return err
+	}
+	request := continuousMove{ProfileToken: profileToken}
+	request.Velocity.PanTilt = &vector2D{X: v.Pan, Y: v.Tilt}
+	if v.Zoom != 0 {
+		request.Velocity.Zoom = &vector1D{X: v.Zoom}
+	}
+	if timeout > 0 {
+		request.Timeout = xsdDuration(timeout)
+	}
+	return c.call(ctx, url, "", request, nil, true)
+}
+
+// Stop moving
+func (c *Client) Stop(ctx context.Context, profileToken string) error {
+	url, err := c.service(ctx, "ptz")
+	if err != nil {
+		return err
+	}
+	return c.call(ctx, url, "", stop{ProfileToken: profileToken, PanTilt: true, Zoom: true}, nil, true)
+}
+
+// GetPresets read PTZ presets of profile
+func (c *Client) GetPresets(ctx context.Context, profileToken string) ([]Preset, error) {
+	url, err := c.service(ctx, "ptz")
+	if err != nil {
+		return nil, err
+	}
+	ret := new(getPresetsResponse)
+	if err := c.call(ctx, url, "", getPresets{ProfileToken: profileToken}, ret, true); err != nil {
+		return nil, err
+	}
+	return ret.Preset, nil
+}
+
+// GotoPreset move to preset
+func (c *Client) GotoPreset(ctx context.Context, profileToken, presetToken string) error {
+	url, err := c.service(ctx, "ptz")
+	if err != nil {
+		return err
+	}
+	return c.call(ctx, url, "", gotoPreset{ProfileToken: profileToken, PresetToken: presetToken}, nil, true)
+}
@@ -0,0 +1,25 @@
+package gotapo
+
+import (
+	"crypto/tls"
+	"net/http"
+)
+
+// Option is setting of Tapo applied in Connect before first request
+type Option func(o *Tapo)
+
+// WithTransport send requests through own transport like Recorder or Replay
+func WithTransport(transport http.RoundTripper) Option {
+	return func(o *Tapo) {
+		o.transport = transport
+	}
+}
+
+// Transport of cam with self-signed certificate
+func defaultTransport() http.RoundTripper {
+	return &http.Transport{
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: true,
+		},
+	}
+}
@@ -0,0 +1,118 @@
+package gotapo
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Mirror of image
+const (
+	// MirrorNone is image not mirrored
+	MirrorNone = "none"
+
+	// MirrorHorizontal is image mirrored left to right
+	MirrorHorizontal = "horizontal"
+
+	// MirrorVertical is image mirrored top to bottom
+	MirrorVertical = "vertical"
+)
+
+// Flip of image in cam (image.switch.flip_type)
+const (
+	flipOff    = "off"
+	flipCenter = "center"
+)
+
+// Orientation of image. Rotate180 is cam on ceiling, Mirror is mirror of image,
+// Rotate90 is corridor view (image.switch.rotate_type).
+// Cam keep only one flip (off, center, horizontal or vertical), so Rotate180
+// with Mirror is written as other mirror (rotate 180 and horizontal mirror is
+// vertical mirror) and read back so
+type Orientation struct {
+	Rotate180 bool
+	Mirror    string
+	Rotate90  bool
+}
+
+// type set orientation
+type setOrientation struct {
+	Method string `json:"method"`
+	Image  struct {
+		Switch struct {
+			FlipType   string `json:"flip_type"`
+			RotateType string `json:"rotate_type"`
+		} `json:"switch"`
+	} `json:"image"`
+}
+
+func setOrientationTemplate(values ...any) setOrientation {
+	t := setOrientation{}
+	t.Method = MethodSet
+	t.Image.Switch.FlipType = values[0].(string)
+	t.Image.Switch.RotateType = values[1].(string)
+	return t
+}
+
+// Orientation from switch section of image
+func orientationOf(flip, rotate string) Orientation {
+	v := Orientation{Mirror: MirrorNone, Rotate90: sBool(rotate)}
+	switch flip {
+	case flipCenter:
+		v.Rotate180 = true
+	case MirrorHorizontal, MirrorVertical:
+		v.Mirror = flip
+	}
+	return v
+}
+
+// Flip of cam for orientation
+func (v Orientation) flip() string {
+	switch {
+	case v.Mirror == MirrorHorizontal && v.Rotate180:
+		return MirrorVertical
+	case v.Mirror == MirrorVertical && v.Rotate180:
+		return MirrorHorizontal
+	case v.Mirror == MirrorHorizontal || v.Mirror == MirrorVertical:
+		return v.Mirror
+	case v.Rotate180:
+		return flipCenter
+	}
+	return flipOff
+}
+
+// GetOrientation read rotate and mirror of image from cam
+func (o *Tapo) GetOrientation() (Orientation, error) {
+	result, err := o.multiple(rotationStatusTemplate())
+	if err != nil {
+		return Orientation{}, err
+	}
+	ret := new(imageRet)
+	if err := json.Unmarshal(result.Result.Responses[0].Result, ret); err != nil {
+		return Orientation{}, ErrResponse
+	}
+	v := orientationOf(ret.Image.Switch.FlipType, ret.Image.Switch.RotateType)
+	o.mu.Lock()
+	o.Orientation = v
+	o.Flip = v.Rotate180
+	o.mu.Unlock()
+	return v, nil
+}
+
+// SetOrientation write rotate and mirror of image to cam. Empty Mirror is MirrorNone
+func (o *Tapo) SetOrientation(v Orientation) error {
+	switch v.Mirror {
+	case "":
+		v.Mirror = MirrorNone
+	case MirrorNone, MirrorHorizontal, MirrorVertical:
+	default:
+		return fmt.Errorf("gotapo: unknown mirror %q", v.Mirror)
+	}
+	if err := o.apply(setOrientationTemplate(v.flip(), new(Types).xBool(v.Rotate90).Default)); err != nil {
+		return err
+	}
+	o.mu.Lock()
+	o.Orientation = v
+	o.Flip = v.Rotate180
+	o.mu.Unlock()
+	return nil
+}
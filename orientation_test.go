@@ -0,0 +1,82 @@
+package gotapo_test
+
+import (
+	"testing"
+
+	"github.com/KusoKaihatsuSha/gotapo"
+	"github.com/KusoKaihatsuSha/gotapo/gotapotest"
+)
+
+func TestOrientation(t *testing.T) {
+	s, c := connect(t, true)
+	for _, v := range []struct {
+		set  gotapo.Orientation
+		flip string
+		get  gotapo.Orientation
+	}{
+		{gotapo.Orientation{}, "off", gotapo.Orientation{Mirror: gotapo.MirrorNone}},
+		{gotapo.Orientation{Rotate180: true}, "center", gotapo.Orientation{Rotate180: true, Mirror: gotapo.MirrorNone}},
+		{gotapo.Orientation{Mirror: gotapo.MirrorHorizontal, Rotate90: true}, "horizontal", gotapo.Orientation{Mirror: gotapo.MirrorHorizontal, Rotate90: true}},
+		{gotapo.Orientation{Rotate180: true, Mirror: gotapo.MirrorHorizontal}, "vertical", gotapo.Orientation{Mirror: gotapo.MirrorVertical}},
+		{gotapo.Orientation{Rotate180: true, Mirror: gotapo.MirrorVertical}, "horizontal", gotapo.Orientation{Mirror: gotapo.MirrorHorizontal}},
+	} {
+		if err := c.SetOrientation(v.set); err != nil {
+			t.Fatal(err)
+		}
+		if flip := s.Value("image", "switch", "flip_type"); flip != v.flip {
+			t.Fatalf("%+v written as %v, want %s", v.set, flip, v.flip)
+		}
+		get, err := c.GetOrientation()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if get != v.get {
+			t.Fatalf("%+v read as %+v, want %+v", v.set, get, v.get)
+		}
+	}
+	if err := c.SetOrientation(gotapo.Orientation{Mirror: "diagonal"}); err == nil {
+		t.Fatal("unknown mirror accepted")
+	}
+}
+
+func TestImageFlipKeepMirror(t *testing.T) {
+	s := gotapotest.NewServer("cam", "secret", true)
+	t.Cleanup(s.Close)
+	s.SetValue("image", "switch", "flip_type", "horizontal")
+	c := gotapo.Connect(s.Host, "cam", "secret")
+	c.Elements.ImageFlip.On()
+	if flip := s.Value("image", "switch", "flip_type"); flip != "vertical" {
+		t.Fatalf("flip %v, want vertical (rotated horizontal mirror)", flip)
+	}
+	if !c.Flip {
+		t.Fatal("flip not remembered")
+	}
+}
+
+// Rotated mirror is read back as other mirror (same image), Flip is false
+func TestImageFlipReadBack(t *testing.T) {
+	for _, v := range []struct {
+		flip string
+		want gotapo.Orientation
+	}{
+		{"off", gotapo.Orientation{Rotate180: true, Mirror: gotapo.MirrorNone}},
+		{"horizontal", gotapo.Orientation{Mirror: gotapo.MirrorVertical}},
+		{"vertical", gotapo.Orientation{Mirror: gotapo.MirrorHorizontal}},
+	} {
+		s := gotapotest.NewServer("cam", "secret", true)
+		s.SetValue("image", "switch", "flip_type", v.flip)
+		c := gotapo.Connect(s.Host, "cam", "secret")
+		c.Elements.ImageFlip.On()
+		if !c.Flip {
+			t.Fatalf("flip %v not set", v.flip)
+		}
+		got, err := c.GetOrientation()
+		s.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != v.want || c.Flip != v.want.Rotate180 {
+			t.Fatalf("orientation after flip of %v %+v (flip %v), want %+v", v.flip, got, c.Flip, v.want)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package gotapo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// OSDTextLimit is max length of label text (symbols, not bytes)
+	OSDTextLimit = 16
+
+	// OSDMaxCoor is max of x_coor and y_coor (virtual frame 10000x10000)
+	OSDMaxCoor = 10000
+)
+
+// OSDItem is element of OSD with position
+type OSDItem struct {
+	Enabled bool
+	X       int
+	Y       int
+}
+
+// OSDLabel is text of OSD with position
+type OSDLabel struct {
+	OSDItem
+	Text string
+}
+
+// OSDFont is font of OSD like display "ntnb", size "auto", color type "auto", color "white".
+// Empty values are not sent to cam
+type OSDFont struct {
+	Display   string
+	Size      string
+	ColorType string
+	Color     string
+}
+
+// OSD is on-screen display of cam. Labels[0] is label_info_1 and so on
+type OSD struct {
+	Date   OSDItem
+	Week   OSDItem
+	Font   OSDFont
+	Labels []OSDLabel
+}
+
+// type set OSD
+type osdSet struct {
+	Method string         `json:"method"`
+	OSD    map[string]any `json:"OSD"`
+}
+
+// type OSD item values
+type osdItem struct {
+	Enabled string `json:"enabled"`
+	XCoor   int    `json:"x_coor"`
+	YCoor   int    `json:"y_coor"`
+}
+
+// type OSD label values
+type osdLabel struct {
+	Enabled string `json:"enabled"`
+	Text    string `json:"text"`
+	XCoor   int    `json:"x_coor"`
+	YCoor   int    `json:"y_coor"`
+}
+
+// type OSD font values
+type osdFont struct {
+	Display   string `json:"display,omitempty"`
+	Size      string `json:"size,omitempty"`
+	ColorType string `json:"color_type,omitempty"`
+	Color     string `json:"color,omitempty"`
+}
+
+// type OSD label values return
+type osdLabelRet struct {
+	Enabled string      `json:"enabled"`
+	Text    string      `json:"text"`
+	XCoor   json.Number `json:"x_coor"`
+	YCoor   json.Number `json:"y_coor"`
+}
+
+// type OSD return with any count of labels
+type osdRet struct {
+	OSD struct {
+		Date      osdLabelRet              `json:"date"`
+		Week      osdLabelRet              `json:"week"`
+		Font      osdFont                  `json:"font"`
+		LabelInfo []map[string]osdLabelRet `json:"label_info"`
+	} `json:"OSD"`
+	ErrorCode int `json:"error_code"`
+}
+
+func osdSetTemplate(values ...any) osdSet {
+	v := values[0].(OSD)
+	t := osdSet{Method: MethodSet, OSD: map[string]any{}}
+	t.OSD["date"] = osdItem{Enabled: new(Types).xBool(v.Date.Enabled).Default, XCoor: v.Date.X, YCoor: v.Date.Y}
+	t.OSD["week"] = osdItem{Enabled: new(Types).xBool(v.Week.Enabled).Default, XCoor: v.Week.X, YCoor: v.Week.Y}
+	if v.Font != (OSDFont{}) {
+		t.OSD["font"] = osdFont(v.Font)
+	}
+	for i, l := range v.Labels {
+		t.OSD["label_info_"+strconv.Itoa(i+1)] = osdLabel{
+			Enabled: new(Types).xBool(l.Enabled).Default,
+			Text:    l.Text,
+			XCoor:   l.X,
+			YCoor:   l.Y,
+		}
+	}
+	return t
+}
+
+func (v osdLabelRet) item() OSDItem {
+	x, _ := v.XCoor.Int64()
+	y, _ := v.YCoor.Int64()
+	return OSDItem{Enabled: sBool(v.Enabled), X: int(x), Y: int(y)}
+}
+
+// GetOSD read OSD of cam
+func (o *Tapo) GetOSD() (OSD, error) {
+	b, err := o.request(getOSDTemplate())
+	if err != nil {
+		return OSD{}, err
+	}
+	ret := new(osdRet)
+	if err := json.NewDecoder(bytes.NewReader(b)).Decode(ret); err != nil {
+		return OSD{}, ErrResponse
+	}
+	if ret.ErrorCode != 0 {
+		return OSD{}, errCode("getOSD", ret.ErrorCode)
+	}
+	v := OSD{
+		Date: ret.OSD.Date.item(),
+		Week: ret.OSD.Week.item(),
+		Font: OSDFont(ret.OSD.Font),
+	}
+	for _, row := range ret.OSD.LabelInfo {
+		for name, l := range row {
+			n, err := strconv.Atoi(strings.TrimPrefix(name, "label_info_"))
+			if err != nil || n < 1 {
+				continue
+			}
+			for len(v.Labels) < n {
+				v.Labels = append(v.Labels, OSDLabel{})
+			}
+			v.Labels[n-1] = OSDLabel{OSDItem: l.item(), Text: l.Text}
+		}
+	}
+	return v, nil
+}
+
+// SetOSD write OSD to cam. Text longer than OSDTextLimit is error (not truncated)
+func (o *Tapo) SetOSD(v OSD) error {
+	if err := v.validate(); err != nil {
+		return err
+	}
+	return o.apply(osdSetTemplate(v))
+}
+
+// UpdateOSD read OSD, change it by fn and write back
+func (o *Tapo) UpdateOSD(fn func(v *OSD)) error {
+	o.config.Lock()
+	defer o.config.Unlock()
+	v, err := o.GetOSD()
+	if err != nil {
+		return err
+	}
+	fn(&v)
+	return o.SetOSD(v)
+}
+
+// Check positions and text before send
+func (v OSD) validate() error {
+	names := []string{"date", "week"}
+	items := []OSDItem{v.Date, v.Week}
+	for i, l := range v.Labels {
+		if err := checkOSDText(l.Text); err != nil {
+			return err
+		}
+		names = append(names, "label "+strconv.Itoa(i+1))
+		items = append(items, l.OSDItem)
+	}
+	for i, item := range items {
+		if item.X < 0 || item.X > OSDMaxCoor || item.Y < 0 || item.Y > OSDMaxCoor {
+			return fmt.Errorf("gotapo: OSD %s position %d,%d out of range 0-%d", names[i], item.X, item.Y, OSDMaxCoor)
+		}
+	}
+	return nil
+}
+
+// Cam cut text longer than limit
+func checkOSDText(text string) error {
+	if n := utf8.RuneCountInString(text); n > OSDTextLimit {
+		return fmt.Errorf("gotapo: OSD text %q has %d symbols, max %d", text, n, OSDTextLimit)
+	}
+	return nil
+}
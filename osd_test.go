@@ -0,0 +1,108 @@
+package gotapo_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/KusoKaihatsuSha/gotapo"
+)
+
+func TestUpdateOSDKeepPositions(t *testing.T) {
+	_, c := connect(t, true)
+	err := c.UpdateOSD(func(v *gotapo.OSD) {
+		v.Labels[0] = gotapo.OSDLabel{OSDItem: gotapo.OSDItem{Enabled: true, X: 100, Y: 9000}, Text: "Gate"}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.UpdateOSD(func(v *gotapo.OSD) {
+		v.Date = gotapo.OSDItem{Enabled: true, X: 5000, Y: 0}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := c.GetOSD()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Date.X != 5000 || !v.Date.Enabled {
+		t.Fatalf("date %+v", v.Date)
+	}
+	if len(v.Labels) == 0 || v.Labels[0].Text != "Gate" || v.Labels[0].X != 100 || v.Labels[0].Y != 9000 {
+		t.Fatalf("labels %+v, want Gate at 100,9000", v.Labels)
+	}
+}
+
+func TestSetOSDValidate(t *testing.T) {
+	_, c := connect(t, true)
+	long := gotapo.OSD{Labels: []gotapo.OSDLabel{{Text: strings.Repeat("x", gotapo.OSDTextLimit+1)}}}
+	if err := c.SetOSD(long); err == nil {
+		t.Fatal("long text accepted")
+	}
+	out := gotapo.OSD{Date: gotapo.OSDItem{X: gotapo.OSDMaxCoor + 1}}
+	if err := c.SetOSD(out); err == nil {
+		t.Fatal("position out of frame accepted")
+	}
+}
+
+func TestLegacyOSDKeepPositions(t *testing.T) {
+	_, c := connect(t, true)
+	err := c.UpdateOSD(func(v *gotapo.OSD) {
+		v.Date = gotapo.OSDItem{Enabled: true, X: 5000, Y: 100}
+		v.Labels[0] = gotapo.OSDLabel{OSDItem: gotapo.OSDItem{X: 100, Y: 9000}, Text: "Gate"}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Settings.OsdText = "Yard"
+	c.On(c.Settings.VisibleOsdText)
+	c.Off(c.Settings.VisibleOsdTime)
+	v, err := c.GetOSD()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Date != (gotapo.OSDItem{X: 5000, Y: 100}) {
+		t.Fatalf("date %+v, want off at 5000,100", v.Date)
+	}
+	if l := v.Labels[0]; l != (gotapo.OSDLabel{OSDItem: gotapo.OSDItem{Enabled: true, X: 100, Y: 9000}, Text: "Yard"}) {
+		t.Fatalf("label %+v, want Yard at 100,9000", l)
+	}
+}
+
+func TestPresetChangeOSD(t *testing.T) {
+	s, c := connect(t, true)
+	c.LastFile = t.TempDir()
+	long := strings.Repeat("x", gotapo.OSDTextLimit+1)
+	s.AddPreset(long)
+	presets, err := c.Presets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(presets) != 3 || presets[2].Name != long {
+		t.Fatalf("presets %+v, want Door, Window and long name", presets)
+	}
+	c.Settings.PresetChangeOsd.Value = true
+	if err := c.UpdateOSD(func(v *gotapo.OSD) {
+		v.Labels[0] = gotapo.OSDLabel{OSDItem: gotapo.OSDItem{X: 100, Y: 9000}, Text: "Gate"}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// position 0 is current, first switch go to Window
+	if err := c.GotoNextPreset(); err != nil {
+		t.Fatal(err)
+	}
+	want := gotapo.OSDLabel{OSDItem: gotapo.OSDItem{Enabled: true, X: 100, Y: 9000}, Text: "Window"}
+	if v, err := c.GetOSD(); err != nil || v.Labels[0] != want {
+		t.Fatalf("label %+v (%v), want %+v", v.Labels, err, want)
+	}
+	moves := len(s.Moves())
+	if err := c.GotoNextPreset(); err == nil || !strings.Contains(err.Error(), long) {
+		t.Fatalf("error %v, want too long name", err)
+	}
+	if len(s.Moves()) != moves+1 {
+		t.Fatal("cam not moved to preset with long name")
+	}
+	if v, err := c.GetOSD(); err != nil || v.Labels[0] != want {
+		t.Fatalf("label %+v (%v), want not changed %+v", v.Labels, err, want)
+	}
+}
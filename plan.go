@@ -0,0 +1,131 @@
+package gotapo
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Modes of windows in record plan
+const (
+	// RecordModeMotion is recording only on detection
+	RecordModeMotion = "1"
+	// RecordModeContinuous is recording all time of window
+	RecordModeContinuous = "2"
+)
+
+// names of record modes for parsing and printing of schedule
+var recordModeNames = map[string]string{
+	RecordModeMotion:     "motion",
+	RecordModeContinuous: "continuous",
+}
+
+func parseRecordMode(s string) (string, error) {
+	for k, v := range recordModeNames {
+		if strings.EqualFold(s, v) || s == k {
+			return k, nil
+		}
+	}
+	return "", fmt.Errorf("gotapo: unknown record mode %q", s)
+}
+
+// AlarmPlan is weekly schedule of alarm (siren and light)
+type AlarmPlan struct {
+	Enabled  bool
+	Schedule Schedule
+}
+
+// RecordPlan is weekly schedule of recording on SD card
+type RecordPlan struct {
+	Enabled  bool
+	Schedule Schedule
+}
+
+// type set plan
+type planSet struct {
+	Method string                                  `json:"method"`
+	Data   map[string]map[string]map[string]string `json:"params"`
+}
+
+func planSetTemplate(values ...any) planSet {
+	t := planSet{}
+	t.Method = values[0].(string)
+	t.Data = map[string]map[string]map[string]string{
+		values[1].(string): {
+			values[2].(string): values[3].(map[string]string),
+		},
+	}
+	return t
+}
+
+// Read section of plan with only string values
+func (o *Tapo) getPlan(request any, module, section string) (map[string]string, error) {
+	result, err := o.multiple(request)
+	if err != nil {
+		return nil, err
+	}
+	ret := map[string]map[string]map[string]any{}
+	if err := json.Unmarshal(result.Result.Responses[0].Result, &ret); err != nil {
+		return nil, ErrResponse
+	}
+	values, ok := ret[module][section]
+	if !ok {
+		return nil, ErrResponse
+	}
+	plan := map[string]string{}
+	for k, v := range values {
+		if str, ok := v.(string); ok {
+			plan[k] = str
+		}
+	}
+	return plan, nil
+}
+
+// AlarmPlan read alarm plan of cam
+func (o *Tapo) AlarmPlan() (AlarmPlan, error) {
+	values, err := o.getPlan(alarmPlanTemplate(), "msg_alarm_plan", "chn1_msg_alarm_plan")
+	if err != nil {
+		return AlarmPlan{}, err
+	}
+	schedule, err := scheduleFromAlarmPlan(values)
+	if err != nil {
+		return AlarmPlan{}, err
+	}
+	return AlarmPlan{Enabled: sBool(values["enabled"]), Schedule: schedule}, nil
+}
+
+// SetAlarmPlan write alarm plan to cam. Mode of windows is ignored
+func (o *Tapo) SetAlarmPlan(plan AlarmPlan) error {
+	if err := plan.Schedule.Validate(); err != nil {
+		return err
+	}
+	values := plan.Schedule.alarmPlan()
+	values["enabled"] = new(Types).xBool(plan.Enabled).Default
+	_, err := o.multiple(planSetTemplate("setAlarmPlan", "msg_alarm_plan", "chn1_msg_alarm_plan", values))
+	return err
+}
+
+// RecordPlan read record plan of cam
+func (o *Tapo) RecordPlan() (RecordPlan, error) {
+	values, err := o.getPlan(recordPlanTemplate(), "record_plan", "chn1_channel")
+	if err != nil {
+		return RecordPlan{}, err
+	}
+	schedule, err := scheduleFromWeekly(values)
+	if err != nil {
+		return RecordPlan{}, err
+	}
+	return RecordPlan{Enabled: sBool(values["enabled"]), Schedule: schedule}, nil
+}
+
+// SetRecordPlan write record plan to cam. Mode of each window is kept
+// (continuous recording if empty)
+func (o *Tapo) SetRecordPlan(plan RecordPlan) error {
+	if err := plan.Schedule.Validate(); err != nil {
+		return err
+	}
+	values := plan.Schedule.weekly()
+	values["enabled"] = new(Types).xBool(plan.Enabled).Default
+	_, err := o.multiple(planSetTemplate("setRecordPlan", "record_plan", "chn1_channel", values))
+	return err
+}
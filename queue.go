@@ -0,0 +1,129 @@
+package gotapo
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Queue of requests to cam. Cam work badly with concurrent requests
+// and every request change session (stok, key, iv), so only one request
+// in time with pause "every" between
+type queue struct {
+	slot  chan struct{}
+	every time.Duration
+	last  time.Time
+	mu    sync.Mutex
+	calls map[string]*flight
+}
+
+// Read request in progress
+type flight struct {
+	done chan struct{}
+	ret  []byte
+	err  error
+}
+
+// WithRateLimit set min pause between requests to cam (default without pause)
+func WithRateLimit(every time.Duration) Option {
+	return func(o *Tapo) {
+		o.rate = every
+	}
+}
+
+func newQueue(every time.Duration) *queue {
+	return &queue{
+		slot:  make(chan struct{}, 1),
+		every: every,
+		calls: map[string]*flight{},
+	}
+}
+
+// Request only read values (get* methods)
+func isRead(method string) bool {
+	method = strings.TrimPrefix(method, MethodMR+":")
+	for _, v := range strings.Split(method, ",") {
+		if !strings.HasPrefix(v, MethodGet) && !strings.HasPrefix(v, "search") {
+			return false
+		}
+	}
+	return method != ""
+}
+
+// Authenticate and send request in queue of cam.
+// Identical concurrent reads are sent once
+func (o *Tapo) request(data any) ([]byte, error) {
+	b, err := json.Marshal(data)
+	if err != nil || !isRead(requestMethod(b)) {
+		return o.enqueue(data)
+	}
+	key := string(b)
+	o.queue.mu.Lock()
+	if f, ok := o.queue.calls[key]; ok {
+		o.queue.mu.Unlock()
+		<-f.done
+		return append([]byte{}, f.ret...), f.err
+	}
+	f := &flight{done: make(chan struct{})}
+	o.queue.calls[key] = f
+	o.queue.mu.Unlock()
+
+	f.ret, f.err = o.enqueue(data)
+	o.queue.mu.Lock()
+	delete(o.queue.calls, key)
+	o.queue.mu.Unlock()
+	close(f.done)
+	return append([]byte{}, f.ret...), f.err
+}
+
+// Wait turn and pause, then authenticate and send.
+// Repeat on transient errors by policy of retry: reads always, other
+// requests only if they were not sent (login or connection failed).
+// Turn is free for other requests in pause before repeat
+func (o *Tapo) enqueue(data any) (ret []byte, err error) {
+	b, _ := json.Marshal(data)
+	read := isRead(requestMethod(b))
+	for attempt := 1; ; attempt++ {
+		var sent bool
+		o.queue.slot <- struct{}{}
+		if wait := time.Until(o.queue.last.Add(o.queue.every)); wait > 0 {
+			time.Sleep(wait)
+		}
+		ret, sent, err = o.attempt(data)
+		o.queue.last = time.Now()
+		<-o.queue.slot
+		if err != nil && !sent {
+			err = &notSentError{err}
+		}
+		if err == nil || !transient(err) || attempt >= o.retry.Attempts {
+			return ret, err
+		}
+		if sent && !read && !connectError(err) {
+			return ret, err
+		}
+		time.Sleep(o.retry.backoff(attempt))
+	}
+}
+
+// Authenticate and send. Sent - request itself was sent (login passed)
+func (o *Tapo) attempt(data any) ([]byte, bool, error) {
+	if err := o.update(); err != nil {
+		return nil, false, err
+	}
+	ret, err := o.send(data, o.hostURLStok, o.Encrypt)
+	return ret, true, err
+}
+
+// Turn On/Off of all Elements and Settings one by one
+func (o *Tapo) lockChildren() {
+	for _, v := range []any{o.Elements, o.Settings} {
+		s := reflect.ValueOf(v).Elem()
+		for i := 0; i < s.NumField(); i++ {
+			if c, ok := s.Field(i).Interface().(*child); ok && c != nil {
+				c.lock = &o.mu
+			}
+		}
+	}
+}
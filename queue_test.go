@@ -0,0 +1,95 @@
+package gotapo_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/KusoKaihatsuSha/gotapo"
+)
+
+func TestQueueRateLimit(t *testing.T) {
+	every := 100 * time.Millisecond
+	s, c := connect(t, true, gotapo.WithRateLimit(every))
+	before := len(s.Requests())
+	for i := 0; i < 3; i++ {
+		if _, err := c.Info(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	list := s.Requests()[before:]
+	if len(list) != 3 {
+		t.Fatalf("requests %d, want 3", len(list))
+	}
+	for i := 1; i < len(list); i++ {
+		if gap := list[i].Time.Sub(list[i-1].Time); gap < every {
+			t.Fatalf("pause between requests %v, want %v", gap, every)
+		}
+	}
+}
+
+func TestQueueCoalesce(t *testing.T) {
+	var hold atomic.Bool
+	release := make(chan struct{})
+	s, c := connect(t, true, gotapo.WithInterceptor(func(call *gotapo.Call, next gotapo.Handler) ([]byte, error) {
+		if hold.Load() && call.Method == gotapo.MethodMR+":getDeviceInfo" {
+			<-release
+		}
+		return next(call)
+	}))
+	hold.Store(true)
+	before := requestsOf(s, "getDeviceInfo")
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.Info(); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	// first read wait in interceptor, others wait it
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+	if n := requestsOf(s, "getDeviceInfo") - before; n != 1 {
+		t.Fatalf("requests to cam %d, want 1", n)
+	}
+}
+
+func TestQueueConcurrentOnOff(t *testing.T) {
+	s, c := connect(t, true)
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			c.Elements.Indicator.On()
+		}()
+		go func() {
+			defer wg.Done()
+			c.Elements.Indicator.Off()
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := c.Info(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	c.Elements.Indicator.Off()
+	if v := s.Value("led", "config", "enabled"); v != "off" {
+		t.Fatalf("led %v, want off", v)
+	}
+	if c.Elements.Indicator.Value {
+		t.Fatal("value of indicator on, want off")
+	}
+}
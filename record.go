@@ -0,0 +1,304 @@
+package gotapo
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Redacted is value of secret fields in fixtures and traces
+const Redacted = "REDACTED"
+
+// Not JSON body is kept in fixture as string: text or hex with this prefix
+// (binary), cut to rawLimit bytes
+const (
+	hexPrefix = "hex:"
+	rawLimit  = 4096
+)
+
+// Fields with secrets (credentials, nonces, digests, session)
+var secretFields = map[string]bool{
+	"password":       true,
+	"digest_passwd":  true,
+	"cnonce":         true,
+	"nonce":          true,
+	"key":            true,
+	"stok":           true,
+	"device_confirm": true,
+}
+
+var stokPath = regexp.MustCompile(`stok=[^/]*`)
+
+// ErrReplay is error of Replay when request not match fixture
+var ErrReplay = errors.New("gotapo: request not match replay fixture")
+
+// Request with clear data and keys for it (inside context of http request)
+type wire struct {
+	plain []byte
+	key   []byte
+	iv    []byte
+}
+
+type wireKey struct{}
+
+// Exchange is one request and response in clear JSON.
+// Encrypted - body was in securePassthrough. Body which is not JSON
+// (like HTML page of error) is JSON string with text or hex of body
+type Exchange struct {
+	Method    string          `json:"method"`
+	Path      string          `json:"path"`
+	Status    int             `json:"status"`
+	Encrypted bool            `json:"encrypted"`
+	Request   json.RawMessage `json:"request"`
+	Response  json.RawMessage `json:"response"`
+}
+
+// Replace values of secret fields in JSON. Not JSON body is kept as string
+func redact(data []byte) json.RawMessage {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return raw(data)
+	}
+	b, _ := json.Marshal(redactValue(v))
+	return b
+}
+
+// Not JSON body as string: text (stok redacted) or hex if binary.
+// Body longer than rawLimit is cut
+func raw(data []byte) json.RawMessage {
+	if len(data) > rawLimit {
+		data = data[:rawLimit]
+	}
+	text := hexPrefix + hex.EncodeToString(data)
+	if utf8.Valid(data) && !strings.HasPrefix(string(data), hexPrefix) {
+		text = stokPath.ReplaceAllString(string(data), "stok="+Redacted)
+	}
+	b, _ := json.Marshal(text)
+	return b
+}
+
+// Body from recorded string (text or hex)
+func unraw(data json.RawMessage) []byte {
+	text := ""
+	if json.Unmarshal(data, &text) != nil {
+		return data
+	}
+	if b, err := hex.DecodeString(strings.TrimPrefix(text, hexPrefix)); err == nil && strings.HasPrefix(text, hexPrefix) {
+		return b
+	}
+	return []byte(text)
+}
+
+func redactValue(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, item := range val {
+			if secretFields[k] {
+				val[k] = Redacted
+				continue
+			}
+			val[k] = redactValue(item)
+		}
+	case []any:
+		for k, item := range val {
+			val[k] = redactValue(item)
+		}
+	}
+	return v
+}
+
+// Method of request. For multipleRequest with methods inside like "multipleRequest:getDeviceInfo,getLedStatus"
+func requestMethod(data []byte) string {
+	request := struct {
+		Method string `json:"method"`
+		Params struct {
+			Requests []struct {
+				Method string `json:"method"`
+			} `json:"requests"`
+		} `json:"params"`
+	}{}
+	json.Unmarshal(data, &request)
+	if request.Method != MethodMR {
+		return request.Method
+	}
+	list := []string{}
+	for _, v := range request.Params.Requests {
+		list = append(list, v.Method)
+	}
+	return request.Method + ":" + strings.Join(list, ",")
+}
+
+// Clear request and keys of http request
+func wireOf(req *http.Request) (*wire, []byte, error) {
+	w, _ := req.Context().Value(wireKey{}).(*wire)
+	if w == nil {
+		w = &wire{}
+	}
+	if req.Body == nil {
+		return w, nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return w, nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	if w.plain != nil {
+		return w, w.plain, nil
+	}
+	return w, body, nil
+}
+
+// Recorder is transport which save requests and responses in clear JSON
+// with redacted secrets. Use with WithTransport and Save for bug report
+type Recorder struct {
+	Next      http.RoundTripper
+	mu        sync.Mutex
+	exchanges []Exchange
+}
+
+// NewRecorder make Recorder over transport (nil - default transport of cam)
+func NewRecorder(next http.RoundTripper) *Recorder {
+	if next == nil {
+		next = defaultTransport()
+	}
+	return &Recorder{Next: next}
+}
+
+// RoundTrip send request and record it
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	w, plain, err := wireOf(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.Next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	e := Exchange{
+		Method:   requestMethod(plain),
+		Path:     stokPath.ReplaceAllString("/"+strings.TrimPrefix(req.URL.Path, "/"), "stok="+Redacted),
+		Status:   resp.StatusCode,
+		Request:  redact(plain),
+		Response: redact(body),
+	}
+	if w.plain != nil {
+		result := new(queryResponse)
+		if json.Unmarshal(body, result) == nil && result.ErrorCode == 0 && result.Result.Response != "" {
+			e.Encrypted = true
+			e.Response = redact([]byte(decodeAES([]byte(decodeB64(result.Result.Response)), w.key, w.iv)))
+		}
+	}
+	r.mu.Lock()
+	r.exchanges = append(r.exchanges, e)
+	r.mu.Unlock()
+	return resp, nil
+}
+
+// Exchanges recorded at this moment
+func (r *Recorder) Exchanges() []Exchange {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Exchange{}, r.exchanges...)
+}
+
+// Save recorded exchanges to JSON fixture
+func (r *Recorder) Save(path string) error {
+	b, err := json.MarshalIndent(r.Exchanges(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0600)
+}
+
+// Replay is transport which answer by recorded exchanges in same order.
+// Encrypted responses are encrypted again by keys of current session
+type Replay struct {
+	mu        sync.Mutex
+	exchanges []Exchange
+	next      int
+}
+
+// NewReplay make Replay from exchanges
+func NewReplay(exchanges []Exchange) *Replay {
+	return &Replay{exchanges: exchanges}
+}
+
+// LoadReplay make Replay from JSON fixture of Recorder
+func LoadReplay(path string) (*Replay, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	exchanges := []Exchange{}
+	if err := json.Unmarshal(b, &exchanges); err != nil {
+		return nil, err
+	}
+	return NewReplay(exchanges), nil
+}
+
+// Left is count of not used exchanges
+func (r *Replay) Left() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.exchanges) - r.next
+}
+
+// RoundTrip answer by next exchange. Method of request must match
+func (r *Replay) RoundTrip(req *http.Request) (*http.Response, error) {
+	w, plain, err := wireOf(req)
+	if err != nil {
+		return nil, err
+	}
+	method := requestMethod(plain)
+	r.mu.Lock()
+	if r.next >= len(r.exchanges) {
+		r.mu.Unlock()
+		return nil, fmt.Errorf("%w: no exchange for %s", ErrReplay, method)
+	}
+	e := r.exchanges[r.next]
+	r.next++
+	r.mu.Unlock()
+	if e.Method != method {
+		return nil, fmt.Errorf("%w: got %s, want %s", ErrReplay, method, e.Method)
+	}
+	body := unraw(e.Response)
+	if e.Encrypted {
+		if w.key == nil {
+			return nil, fmt.Errorf("%w: %s must be encrypted", ErrReplay, method)
+		}
+		ret := queryResponse{}
+		ret.Result.Response = encodeB64(encodeAES(body, w.key, w.iv))
+		body, _ = json.Marshal(ret)
+	}
+	status := e.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json; charset=UTF-8"}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,63 @@
+package gotapo_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/KusoKaihatsuSha/gotapo"
+)
+
+func TestRecorderKeepRawBody(t *testing.T) {
+	bodies := map[string][]byte{
+		"/json":   []byte(`{"error_code":0,"result":{"stok":"abc","password":"secret"}}`),
+		"/html":   []byte(`<html>502 Bad Gateway</html>`),
+		"/binary": {0xff, 0x00, 0xfe},
+		"/long":   bytes.Repeat([]byte("x"), 5000),
+	}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(bodies[r.URL.Path])
+	}))
+	defer s.Close()
+	recorder := gotapo.NewRecorder(http.DefaultTransport)
+	client := &http.Client{Transport: recorder}
+	paths := []string{"/json", "/html", "/binary", "/long"}
+	for _, path := range paths {
+		resp, err := client.Post(s.URL+path, "application/json", strings.NewReader(`{"method":"get"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	e := recorder.Exchanges()
+	if strings.Contains(string(e[0].Response), "secret") || strings.Contains(string(e[0].Response), "abc") {
+		t.Fatalf("secrets in %s", e[0].Response)
+	}
+	text := ""
+	if err := json.Unmarshal(e[1].Response, &text); err != nil || text != string(bodies["/html"]) {
+		t.Fatalf("html %s, want text of body", e[1].Response)
+	}
+	if err := json.Unmarshal(e[2].Response, &text); err != nil || text != "hex:ff00fe" {
+		t.Fatalf("binary %s, want hex", e[2].Response)
+	}
+	if err := json.Unmarshal(e[3].Response, &text); err != nil || len(text) != 4096 {
+		t.Fatalf("long body %d bytes, want cut to 4096", len(text))
+	}
+
+	client = &http.Client{Transport: gotapo.NewReplay(e)}
+	for _, path := range paths {
+		resp, err := client.Post(s.URL+path, "application/json", strings.NewReader(`{"method":"get"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if (path == "/html" || path == "/binary") && !bytes.Equal(b, bodies[path]) {
+			t.Fatalf("replay of %s is %q, want %q", path, b, bodies[path])
+		}
+	}
+}
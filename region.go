@@ -0,0 +1,198 @@
+package gotapo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MotionGrid is size of coordinate grid of cam for regions
+const MotionGrid = 10000
+
+// Region is rectangular area of motion detection on coordinate grid of cam
+type Region struct {
+	ID     string
+	X      int
+	Y      int
+	Width  int
+	Height int
+}
+
+// type region values
+type regionInfo struct {
+	Name   string `json:".name,omitempty"`
+	Type   string `json:".type,omitempty"`
+	XCoor  string `json:"x_coor"`
+	YCoor  string `json:"y_coor"`
+	Width  string `json:"width"`
+	Height string `json:"height"`
+}
+
+// type get regions
+type motionRegion struct {
+	Method string `json:"method"`
+	Data   struct {
+		Type struct {
+			Table []string `json:"table"`
+		} `json:"motion_detection"`
+	} `json:"params"`
+}
+
+// type get regions return (inside multipleRequest)
+type motionRegionRet struct {
+	MotionDetection struct {
+		RegionInfo []map[string]regionInfo `json:"region_info"`
+	} `json:"motion_detection"`
+}
+
+// type add region
+type addMotionRegion struct {
+	Method          string `json:"method"`
+	MotionDetection struct {
+		RegionInfo []regionInfo `json:"region_info"`
+	} `json:"motion_detection"`
+}
+
+// type remove region
+type removeMotionRegion struct {
+	Method          string `json:"method"`
+	MotionDetection struct {
+		RegionInfo struct {
+			Name []string `json:"name"`
+		} `json:"region_info"`
+	} `json:"motion_detection"`
+}
+
+func motionRegionTemplate(values ...any) motionRegion {
+	t := motionRegion{}
+	t.Method = "getDetectionConfig"
+	t.Data.Type.Table = []string{"region_info"}
+	return t
+}
+
+func addMotionRegionTemplate(values ...any) addMotionRegion {
+	t := addMotionRegion{}
+	t.Method = "add"
+	r := values[0].(Region)
+	t.MotionDetection.RegionInfo = []regionInfo{
+		{
+			XCoor:  strconv.Itoa(r.X),
+			YCoor:  strconv.Itoa(r.Y),
+			Width:  strconv.Itoa(r.Width),
+			Height: strconv.Itoa(r.Height),
+		},
+	}
+	return t
+}
+
+func removeMotionRegionTemplate(values ...any) removeMotionRegion {
+	t := removeMotionRegion{}
+	t.Method = "delete"
+	t.MotionDetection.RegionInfo.Name = values[0].([]string)
+	return t
+}
+
+// Validate check region inside coordinate grid of cam
+func (r Region) Validate() error {
+	if r.Width <= 0 || r.Height <= 0 {
+		return fmt.Errorf("gotapo: region %dx%d is empty", r.Width, r.Height)
+	}
+	if r.X < 0 || r.Y < 0 || r.X+r.Width > MotionGrid || r.Y+r.Height > MotionGrid {
+		return fmt.Errorf("gotapo: region (%d,%d %dx%d) out of grid %d", r.X, r.Y, r.Width, r.Height, MotionGrid)
+	}
+	return nil
+}
+
+// MotionRegions read motion detection regions of cam
+func (o *Tapo) MotionRegions() ([]Region, error) {
+	result, err := o.multiple(motionRegionTemplate())
+	if err != nil {
+		return nil, err
+	}
+	ret := new(motionRegionRet)
+	if err := json.Unmarshal(result.Result.Responses[0].Result, ret); err != nil {
+		return nil, ErrResponse
+	}
+	regions := []Region{}
+	for _, v := range ret.MotionDetection.RegionInfo {
+		for id, info := range v {
+			r := Region{ID: id}
+			r.X, _ = strconv.Atoi(info.XCoor)
+			r.Y, _ = strconv.Atoi(info.YCoor)
+			r.Width, _ = strconv.Atoi(info.Width)
+			r.Height, _ = strconv.Atoi(info.Height)
+			regions = append(regions, r)
+		}
+	}
+	sort.Slice(regions, func(i, j int) bool {
+		return regionNumber(regions[i].ID) < regionNumber(regions[j].ID)
+	})
+	return regions, nil
+}
+
+// AddMotionRegion add motion detection region. ID of region is ignored
+func (o *Tapo) AddMotionRegion(r Region) error {
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	return o.apply(addMotionRegionTemplate(r))
+}
+
+// RemoveMotionRegion remove motion detection region by ID
+func (o *Tapo) RemoveMotionRegion(id string) error {
+	return o.apply(removeMotionRegionTemplate([]string{id}))
+}
+
+// SetMotionRegions replace all motion detection regions.
+// Empty list remove all regions (detection on full frame).
+// If cam fail in the middle previous regions are restored
+func (o *Tapo) SetMotionRegions(regions []Region) error {
+	for _, r := range regions {
+		if err := r.Validate(); err != nil {
+			return err
+		}
+	}
+	old, err := o.MotionRegions()
+	if err != nil {
+		return err
+	}
+	if err := o.replaceRegions(old, regions); err != nil {
+		current, errRead := o.MotionRegions()
+		if errRead != nil {
+			return errors.Join(err, errRead)
+		}
+		if errRestore := o.replaceRegions(current, old); errRestore != nil {
+			return errors.Join(err, fmt.Errorf("gotapo: regions not restored: %w", errRestore))
+		}
+		return err
+	}
+	return nil
+}
+
+// Remove regions "old" and add "regions"
+func (o *Tapo) replaceRegions(old, regions []Region) error {
+	if len(old) > 0 {
+		ids := []string{}
+		for _, r := range old {
+			ids = append(ids, r.ID)
+		}
+		if err := o.apply(removeMotionRegionTemplate(ids)); err != nil {
+			return err
+		}
+	}
+	for _, r := range regions {
+		if err := o.apply(addMotionRegionTemplate(r)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Number of region from name like "region_info_1"
+func regionNumber(id string) int {
+	n, _ := strconv.Atoi(id[strings.LastIndex(id, "_")+1:])
+	return n
+}
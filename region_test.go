@@ -0,0 +1,91 @@
+package gotapo_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/KusoKaihatsuSha/gotapo"
+)
+
+func regionsEqual(a, b []gotapo.Region) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		a[i].ID, b[i].ID = "", ""
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSetMotionRegions(t *testing.T) {
+	_, c := connect(t, true)
+	want := []gotapo.Region{
+		{X: 0, Y: 0, Width: 5000, Height: 5000},
+		{X: 5000, Y: 5000, Width: 5000, Height: 5000},
+	}
+	if err := c.SetMotionRegions(want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.MotionRegions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !regionsEqual(got, want) {
+		t.Fatalf("regions %v, want %v", got, want)
+	}
+	if err := c.SetMotionRegions([]gotapo.Region{{X: 9000, Y: 0, Width: 2000, Height: 10}}); err == nil {
+		t.Fatal("region out of grid accepted")
+	}
+}
+
+func TestSetMotionRegionsRestore(t *testing.T) {
+	s, c := connect(t, true)
+	old := []gotapo.Region{
+		{X: 100, Y: 100, Width: 1000, Height: 1000},
+		{X: 2000, Y: 2000, Width: 1000, Height: 1000},
+	}
+	if err := c.SetMotionRegions(old); err != nil {
+		t.Fatal(err)
+	}
+	s.TableLimit = 3
+	err := c.SetMotionRegions([]gotapo.Region{
+		{X: 0, Y: 0, Width: 10, Height: 10},
+		{X: 10, Y: 10, Width: 10, Height: 10},
+		{X: 20, Y: 20, Width: 10, Height: 10},
+		{X: 30, Y: 30, Width: 10, Height: 10},
+	})
+	if !errors.Is(err, gotapo.ErrInvalidParams) {
+		t.Fatalf("error %v, want invalid params", err)
+	}
+	got, err := c.MotionRegions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !regionsEqual(got, old) {
+		t.Fatalf("regions %v after failure, want restored %v", got, old)
+	}
+}
+
+func TestDetectSensitivityLegacy(t *testing.T) {
+	s, c := connect(t, true)
+	for level, want := range map[int]string{1: "20", 2: "50", 3: "80"} {
+		c.Settings.DetectSensitivity = level
+		c.Elements.DetectMode.On()
+		if v := s.Value("motion_detection", "motion_det", "digital_sensitivity"); v != want {
+			t.Fatalf("digital sensitivity %v for level %d, want %s", v, level, want)
+		}
+	}
+}
+
+func TestMotionSensitivityRaw(t *testing.T) {
+	s, c := connect(t, true)
+	if err := c.Detectors.Motion.SetSensitivity(3); err != nil {
+		t.Fatal(err)
+	}
+	if v := s.Value("motion_detection", "motion_det", "digital_sensitivity"); v != "3" {
+		t.Fatalf("digital sensitivity %v, want raw 3", v)
+	}
+}
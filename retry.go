@@ -0,0 +1,216 @@
+package gotapo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// Retry is policy of repeat of request on network errors and HTTP 5xx.
+// Pause before repeat start from Delay and doubled up to MaxDelay.
+// Errors of cam (error_code) and login are not repeated. Only reads are
+// repeated after request was sent (set, do may be done by cam already)
+type Retry struct {
+	Attempts int
+	Delay    time.Duration
+	MaxDelay time.Duration
+}
+
+// DefaultRetry is 3 attempts with pauses 0.5s and 1s
+var DefaultRetry = Retry{Attempts: 3, Delay: 500 * time.Millisecond, MaxDelay: 5 * time.Second}
+
+// WithRetry set policy of repeat (default without repeat)
+func WithRetry(r Retry) Option {
+	return func(o *Tapo) {
+		o.retry = r
+	}
+}
+
+// Pause before attempt (from 1)
+func (r Retry) backoff(attempt int) time.Duration {
+	d := r.Delay
+	for i := 1; i < attempt && (r.MaxDelay <= 0 || d < r.MaxDelay); i++ {
+		d *= 2
+	}
+	if r.MaxDelay > 0 && d > r.MaxDelay {
+		d = r.MaxDelay
+	}
+	return d
+}
+
+// StatusError is HTTP answer of cam not 200 OK
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("gotapo: cam answered with http status %d", e.StatusCode)
+}
+
+// Error may disappear on repeat (network or cam busy)
+func transient(err error) bool {
+	var status *StatusError
+	if errors.As(err, &status) {
+		return status.StatusCode >= 500 || status.StatusCode == http.StatusTooManyRequests
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// Connection to cam is not made, request is not sent
+func connectError(err error) bool {
+	var opErr *net.OpError
+	return (errors.As(err, &opErr) && opErr.Op == "dial") || errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// notSentError is error of request which was not sent to cam (login or
+// connection failed before), so request is safe to repeat
+type notSentError struct {
+	err error
+}
+
+func (e *notSentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *notSentError) Unwrap() error {
+	return e.err
+}
+
+// Request was not sent to cam: login or connection failed
+func notSent(err error) bool {
+	var notSentErr *notSentError
+	var loginErr *LoginError
+	return errors.As(err, &notSentErr) || errors.As(err, &loginErr) || connectError(err)
+}
+
+// LoginError is failed login. Cam lock login for some minutes
+// after several wrong attempts, so login is not repeated if Attempts <= 1
+type LoginError struct {
+	Code     int
+	Attempts int // remaining attempts before lock, -1 if cam not say
+	Lock     time.Duration
+}
+
+func (e *LoginError) Error() string {
+	switch {
+	case e.Locked():
+		return fmt.Sprintf("gotapo: login locked by cam for %s", e.Lock.Round(time.Second))
+	case e.Attempts >= 0:
+		return fmt.Sprintf("gotapo: login failed with error code %d, %d attempts left before lock", e.Code, e.Attempts)
+	}
+	return fmt.Sprintf("gotapo: login failed with error code %d", e.Code)
+}
+
+// Locked is cam refuse all logins now
+func (e *LoginError) Locked() bool {
+	return e.Lock > 0
+}
+
+// Unwrap is error of catalog for Code (for errors.Is)
+func (e *LoginError) Unwrap() error {
+	if known := ErrorByCode(e.Code); known != nil {
+		return known
+	}
+	return nil
+}
+
+// Last attempt (or already locked), next wrong login will lock cam
+func (e *LoginError) lastAttempt() bool {
+	return e.Locked() || (e.Attempts >= 0 && e.Attempts <= 1)
+}
+
+// type failed login return
+type loginFailRet struct {
+	ErrorCode int `json:"error_code"`
+	Result    struct {
+		Data struct {
+			Code    int  `json:"code"`
+			SecLeft int  `json:"sec_left"`
+			Time    *int `json:"time"`
+			MaxTime int  `json:"max_time"`
+		} `json:"data"`
+	} `json:"result"`
+}
+
+// Error of failed login from answer of cam
+func loginError(b []byte) *LoginError {
+	ret := new(loginFailRet)
+	json.Unmarshal(b, ret)
+	e := &LoginError{Code: ret.ErrorCode, Attempts: -1}
+	data := ret.Result.Data
+	if data.Code != 0 {
+		e.Code = data.Code
+	}
+	if data.SecLeft > 0 {
+		e.Lock = time.Duration(data.SecLeft) * time.Second
+		e.Attempts = 0
+	}
+	if data.Time != nil && data.MaxTime > 0 && !e.Locked() {
+		e.Attempts = *data.Time
+	}
+	return e
+}
+
+// Remember lock of login. Requests will fail without login until end of lock.
+// After last attempt login with same credentials is not sent anymore
+// (until change of credentials or ResetLogin)
+func (o *Tapo) locked(err *LoginError) error {
+	o.state.Lock()
+	defer o.state.Unlock()
+	switch {
+	case err.Locked():
+		o.lockedUntil = time.Now().Add(err.Lock)
+	case err.lastAttempt():
+		o.loginErr = err
+	}
+	return err
+}
+
+// Error if login is locked now
+func (o *Tapo) lockout() error {
+	o.state.Lock()
+	defer o.state.Unlock()
+	if left := time.Until(o.lockedUntil); left > 0 {
+		return &LoginError{Code: ErrLocked.Code, Lock: left}
+	}
+	if o.loginErr != nil {
+		return o.loginErr
+	}
+	return nil
+}
+
+// ResetLogin allow login after last failed attempt (like after change of
+// password on cam). Lock by cam is kept until its end.
+// Fallback of AuthAuto to "admin" is reset to user too
+func (o *Tapo) ResetLogin() {
+	o.queue.slot <- struct{}{}
+	defer func() {
+		<-o.queue.slot
+	}()
+	o.resetLogin()
+}
+
+// Forget failed login (inside turn of queue)
+func (o *Tapo) resetLogin() {
+	o.state.Lock()
+	defer o.state.Unlock()
+	o.loginErr = nil
+	if o.authInfo.Fallback {
+		o.authUser = ""
+		o.authInfo.Fallback = false
+	}
+}
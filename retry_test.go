@@ -0,0 +1,111 @@
+package gotapo_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/KusoKaihatsuSha/gotapo"
+	"github.com/KusoKaihatsuSha/gotapo/gotapotest"
+)
+
+var testRetry = gotapo.Retry{Attempts: 3, Delay: 10 * time.Millisecond}
+
+func TestRetryRead(t *testing.T) {
+	s, c := connect(t, true, gotapo.WithRetry(testRetry))
+	s.Fail(gotapotest.FaultDrop, 1)
+	if _, err := c.Info(); err != nil {
+		t.Fatalf("read not repeated: %v", err)
+	}
+}
+
+func TestRetryNotRepeatSent(t *testing.T) {
+	s, c := connect(t, true, gotapo.WithRetry(testRetry))
+	s.Fail(gotapotest.FaultDrop, 1)
+	if err := c.Restart(); err == nil {
+		t.Fatal("lost answer not reported")
+	}
+	if n := s.Reboots(); n != 1 {
+		t.Fatalf("reboots %d, want 1", n)
+	}
+}
+
+func TestRetryNotSent(t *testing.T) {
+	s, c := connect(t, true, gotapo.WithRetry(testRetry))
+	s.Delay = 0
+	// login is dropped, request is not sent
+	s.Fail(gotapotest.FaultTimeout, 1)
+	if err := c.Restart(); err != nil {
+		t.Fatalf("not sent request not repeated: %v", err)
+	}
+	if n := s.Reboots(); n != 1 {
+		t.Fatalf("reboots %d, want 1", n)
+	}
+}
+
+func TestRetryFreeQueueInPause(t *testing.T) {
+	s, c := connect(t, true, gotapo.WithRetry(gotapo.Retry{Attempts: 2, Delay: time.Second}))
+	s.Fail(gotapotest.FaultDrop, 1)
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if _, err := c.Info(); err != nil {
+			t.Error(err)
+		}
+	}()
+	// wait first attempt of Info
+	for i := 0; i < 100 && s.Logins() == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(100 * time.Millisecond)
+	start := time.Now()
+	if err := c.SetLed(false); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d > 500*time.Millisecond {
+		t.Fatalf("request waited %s for pause of other request", d)
+	}
+	wg.Wait()
+}
+
+func TestLoginNewCredentials(t *testing.T) {
+	s, _ := connect(t, true)
+	s.MaxAttempts = 3
+	mu := sync.Mutex{}
+	password := "wrong"
+	provider := gotapo.CredentialFunc(func() (string, []byte, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return "cam", []byte(password), nil
+	})
+	c := gotapo.Connect(s.Host, "", "", gotapo.WithCredentials(provider), gotapo.WithAuthMode(gotapo.AuthSecure))
+	for i := 0; i < 3; i++ {
+		if _, err := c.Info(); err == nil {
+			t.Fatal("login with wrong password")
+		}
+	}
+	mu.Lock()
+	password = "secret"
+	mu.Unlock()
+	if _, err := c.Info(); err != nil {
+		t.Fatalf("new credentials not tried: %v", err)
+	}
+}
+
+func TestResetLogin(t *testing.T) {
+	s, _ := connect(t, true)
+	s.MaxAttempts = 3
+	c := gotapo.Connect(s.Host, "cam", "wrong", gotapo.WithAuthMode(gotapo.AuthSecure))
+	for i := 0; i < 3; i++ {
+		if _, err := c.Info(); errors.Is(err, gotapo.ErrLocked) {
+			t.Fatal("cam locked")
+		}
+	}
+	c.ResetLogin()
+	// login is sent again and it is last attempt
+	if _, err := c.Info(); !errors.Is(err, gotapo.ErrLocked) {
+		t.Fatalf("error %v, want lock after reset", err)
+	}
+}
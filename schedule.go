@@ -0,0 +1,438 @@
+package gotapo
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Weekdays is bitmask of days of week (bit 0 - Sunday, as time.Weekday)
+type Weekdays uint8
+
+const (
+	// Sunday bit of Weekdays
+	Sunday Weekdays = 1 << iota
+	// Monday bit of Weekdays
+	Monday
+	// Tuesday bit of Weekdays
+	Tuesday
+	// Wednesday bit of Weekdays
+	Wednesday
+	// Thursday bit of Weekdays
+	Thursday
+	// Friday bit of Weekdays
+	Friday
+	// Saturday bit of Weekdays
+	Saturday
+
+	// Workdays is Monday - Friday
+	Workdays = Monday | Tuesday | Wednesday | Thursday | Friday
+	// Weekend is Saturday and Sunday
+	Weekend = Saturday | Sunday
+	// Everyday is all days of week
+	Everyday = Workdays | Weekend
+)
+
+// names of days like in cam (record plan) and short names for parsing
+var dayNames = [7][2]string{
+	{"sunday", "sun"},
+	{"monday", "mon"},
+	{"tuesday", "tue"},
+	{"wednesday", "wed"},
+	{"thursday", "thu"},
+	{"friday", "fri"},
+	{"saturday", "sat"},
+}
+
+// Has check day in bitmask
+func (d Weekdays) Has(day time.Weekday) bool {
+	return d&(1<<day) != 0
+}
+
+// String like "mon-fri" or "sat,sun"
+func (d Weekdays) String() string {
+	if d&Everyday == Everyday {
+		return "daily"
+	}
+	parts := []string{}
+	// from Monday to Sunday
+	for i := 1; i <= 7; i++ {
+		if !d.Has(time.Weekday(i % 7)) {
+			continue
+		}
+		j := i
+		for j < 7 && d.Has(time.Weekday((j+1)%7)) {
+			j++
+		}
+		switch {
+		case j-i >= 2:
+			parts = append(parts, dayNames[i%7][1]+"-"+dayNames[j%7][1])
+		case j > i:
+			parts = append(parts, dayNames[i%7][1], dayNames[j%7][1])
+		default:
+			parts = append(parts, dayNames[i%7][1])
+		}
+		i = j
+	}
+	return strings.Join(parts, ",")
+}
+
+// ParseWeekdays parse "mon-fri", "sat,sun", "daily"
+func ParseWeekdays(s string) (Weekdays, error) {
+	var d Weekdays
+	for _, part := range strings.Split(strings.ToLower(strings.TrimSpace(s)), ",") {
+		part = strings.TrimSpace(part)
+		switch part {
+		case "daily", "everyday", "*":
+			d |= Everyday
+			continue
+		case "workdays":
+			d |= Workdays
+			continue
+		case "weekend":
+			d |= Weekend
+			continue
+		}
+		from, to, isRange := strings.Cut(part, "-")
+		start, err := parseDay(from)
+		if err != nil {
+			return 0, err
+		}
+		end := start
+		if isRange {
+			if end, err = parseDay(to); err != nil {
+				return 0, err
+			}
+		}
+		for day := start; ; day = (day + 1) % 7 {
+			d |= 1 << day
+			if day == end {
+				break
+			}
+		}
+	}
+	return d, nil
+}
+
+func parseDay(s string) (time.Weekday, error) {
+	for k, v := range dayNames {
+		if s == v[0] || s == v[1] {
+			return time.Weekday(k), nil
+		}
+	}
+	return 0, fmt.Errorf("gotapo: unknown day %q", s)
+}
+
+// Clock is time of day in minutes from midnight (0-1440)
+type Clock int
+
+// NewClock make Clock from hours and minutes
+func NewClock(hour, minute int) Clock {
+	return Clock(hour*60 + minute)
+}
+
+// Hour of clock
+func (c Clock) Hour() int {
+	return int(c) / 60
+}
+
+// Minute of clock
+func (c Clock) Minute() int {
+	return int(c) % 60
+}
+
+// String like "22:00"
+func (c Clock) String() string {
+	return fmt.Sprintf("%02d:%02d", c.Hour(), c.Minute())
+}
+
+// encoded like in cam "2200"
+func (c Clock) encode() string {
+	return fmt.Sprintf("%02d%02d", c.Hour(), c.Minute())
+}
+
+// ParseClock parse "22:00" or "2200". "24:00" is end of day
+func ParseClock(s string) (Clock, error) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), ":", "")
+	if len(s) != 4 {
+		return 0, fmt.Errorf("gotapo: bad time %q", s)
+	}
+	h, errH := strconv.Atoi(s[:2])
+	m, errM := strconv.Atoi(s[2:])
+	if errH != nil || errM != nil || m > 59 || h*60+m > 1440 {
+		return 0, fmt.Errorf("gotapo: bad time %q", s)
+	}
+	return NewClock(h, m), nil
+}
+
+// Window is time window on days of week.
+// End before Start means window through midnight (to next day).
+// Mode is used only in record plan (RecordModeContinuous if empty)
+type Window struct {
+	Days  Weekdays
+	Start Clock
+	End   Clock
+	Mode  string
+}
+
+// String like "mon-fri 22:00-06:00" or "sat,sun 00:00-24:00 motion"
+func (w Window) String() string {
+	s := w.Days.String() + " " + w.Start.String() + "-" + w.End.String()
+	if name, ok := recordModeNames[w.Mode]; ok {
+		s += " " + name
+	}
+	return s
+}
+
+// Validate check window: days not empty, times inside day, known mode.
+// End "00:00" is not allowed, "24:00" is midnight
+func (w Window) Validate() error {
+	if w.Days&Everyday == 0 {
+		return fmt.Errorf("gotapo: window %q without days", w.String())
+	}
+	if w.Start < 0 || w.Start >= 1440 {
+		return fmt.Errorf("gotapo: window start %s out of day", w.Start)
+	}
+	if w.End <= 0 || w.End > 1440 {
+		return fmt.Errorf("gotapo: window end %s out of day (midnight is 24:00)", w.End)
+	}
+	if w.Start == w.End {
+		return fmt.Errorf("gotapo: window %s-%s is empty", w.Start, w.End)
+	}
+	if _, ok := recordModeNames[w.Mode]; !ok && w.Mode != "" {
+		return fmt.Errorf("gotapo: unknown record mode %q", w.Mode)
+	}
+	return nil
+}
+
+// Schedule is weekly schedule. Used for alarm and record plans
+type Schedule []Window
+
+// String like "mon-fri 22:00-06:00; sat,sun 00:00-24:00"
+func (s Schedule) String() string {
+	parts := []string{}
+	for _, v := range s {
+		parts = append(parts, v.String())
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Validate check all windows of schedule
+func (s Schedule) Validate() error {
+	for _, v := range s {
+		if err := v.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseSchedule parse schedule like "mon-fri 22:00-06:00; sat,sun 00:00-24:00 motion"
+func ParseSchedule(s string) (Schedule, error) {
+	schedule := Schedule{}
+	for _, part := range strings.Split(s, ";") {
+		fields := strings.Fields(part)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 2 || len(fields) > 3 {
+			return nil, fmt.Errorf("gotapo: bad window %q", strings.TrimSpace(part))
+		}
+		w := Window{}
+		var err error
+		if w.Days, err = ParseWeekdays(fields[0]); err != nil {
+			return nil, err
+		}
+		from, to, ok := strings.Cut(fields[1], "-")
+		if !ok {
+			return nil, fmt.Errorf("gotapo: bad window %q", strings.TrimSpace(part))
+		}
+		if w.Start, err = ParseClock(from); err != nil {
+			return nil, err
+		}
+		if w.End, err = ParseClock(to); err != nil {
+			return nil, err
+		}
+		if len(fields) == 3 {
+			if w.Mode, err = parseRecordMode(fields[2]); err != nil {
+				return nil, err
+			}
+		}
+		if err := w.Validate(); err != nil {
+			return nil, err
+		}
+		schedule = append(schedule, w)
+	}
+	return schedule, nil
+}
+
+// Encode to alarm plan of cam: "alarm_plan_1": "2200-0600,62".
+// Window of alarm plan has own days, so window through midnight is not split
+// (cam take end before start as next day, unlike plan by days of record).
+// Midnight as end is "0000" like in cam ("0000-0000" is whole day)
+func (s Schedule) alarmPlan() map[string]string {
+	plan := map[string]string{}
+	for k, v := range s {
+		end := v.End.encode()
+		if v.End == 1440 {
+			end = "0000"
+		}
+		plan["alarm_plan_"+strconv.Itoa(k+1)] = v.Start.encode() + "-" + end + "," + strconv.Itoa(int(v.Days&Everyday))
+	}
+	plan["alarm_plan_num"] = strconv.Itoa(len(s))
+	return plan
+}
+
+// Decode alarm plan of cam. Only alarm_plan_num windows are read (cam may keep
+// old ones), without alarm_plan_num - all from alarm_plan_1. End "0000" is midnight (24:00), so "0000-0000" is whole day.
+// Windows without days are skipped (cam not use them)
+func scheduleFromAlarmPlan(plan map[string]string) (Schedule, error) {
+	num := 0
+	if value, ok := plan["alarm_plan_num"]; ok {
+		var err error
+		if num, err = strconv.Atoi(value); err != nil {
+			return nil, fmt.Errorf("gotapo: bad alarm_plan_num %q", value)
+		}
+	} else {
+		for plan["alarm_plan_"+strconv.Itoa(num+1)] != "" {
+			num++
+		}
+	}
+	schedule := Schedule{}
+	for i := 1; i <= num; i++ {
+		value, ok := plan["alarm_plan_"+strconv.Itoa(i)]
+		if !ok {
+			return nil, fmt.Errorf("gotapo: alarm plan %d of %d not found", i, num)
+		}
+		times, days, ok := strings.Cut(value, ",")
+		from, to, okTimes := strings.Cut(times, "-")
+		mask, err := strconv.Atoi(days)
+		if !ok || !okTimes || err != nil {
+			return nil, fmt.Errorf("gotapo: bad plan %q", value)
+		}
+		w := Window{Days: Weekdays(mask) & Everyday}
+		if w.Start, err = ParseClock(from); err != nil {
+			return nil, err
+		}
+		if w.End, err = ParseClock(to); err != nil {
+			return nil, err
+		}
+		if w.End == 0 {
+			w.End = 1440
+		}
+		if w.Days == 0 {
+			continue
+		}
+		schedule = append(schedule, w)
+	}
+	return schedule, nil
+}
+
+// Encode to plan by days of cam: "monday": "[\"2200-2400:2\"]".
+// Window through midnight is split on two days
+func (s Schedule) weekly() map[string]string {
+	days := [7][]string{}
+	for _, v := range s {
+		mode := v.Mode
+		if mode == "" {
+			mode = RecordModeContinuous
+		}
+		for day := time.Sunday; day <= time.Saturday; day++ {
+			if !v.Days.Has(day) {
+				continue
+			}
+			if v.End > v.Start {
+				days[day] = append(days[day], v.Start.encode()+"-"+v.End.encode()+":"+mode)
+				continue
+			}
+			days[day] = append(days[day], v.Start.encode()+"-2400:"+mode)
+			next := (day + 1) % 7
+			days[next] = append(days[next], "0000-"+v.End.encode()+":"+mode)
+		}
+	}
+	plan := map[string]string{}
+	for day, list := range days {
+		sort.Strings(list)
+		if list == nil {
+			list = []string{}
+		}
+		b, _ := json.Marshal(list)
+		plan[dayNames[day][0]] = string(b)
+	}
+	return plan
+}
+
+// Decode plan by days of cam. Windows split on midnight ("2200-2400" and
+// "0000-0600" of next day) are joined back, same windows on different days too
+func scheduleFromWeekly(plan map[string]string) (Schedule, error) {
+	days := [7][]Window{}
+	for day, names := range dayNames {
+		value, ok := plan[names[0]]
+		if !ok || value == "" {
+			continue
+		}
+		list := []string{}
+		if err := json.Unmarshal([]byte(value), &list); err != nil {
+			return nil, fmt.Errorf("gotapo: bad plan %q", value)
+		}
+		for _, v := range list {
+			times, mode, _ := strings.Cut(v, ":")
+			from, to, ok := strings.Cut(times, "-")
+			if !ok {
+				return nil, fmt.Errorf("gotapo: bad plan %q", v)
+			}
+			w := Window{Days: 1 << day, Mode: mode}
+			var err error
+			if w.Start, err = ParseClock(from); err != nil {
+				return nil, err
+			}
+			if w.End, err = ParseClock(to); err != nil {
+				return nil, err
+			}
+			days[day] = append(days[day], w)
+		}
+	}
+	// join window to midnight with window from midnight of next day
+	used := [7][]bool{}
+	for day := range days {
+		used[day] = make([]bool, len(days[day]))
+	}
+	for day := range days {
+		for k, w := range days[day] {
+			if w.End != 1440 || w.Start == 0 {
+				continue
+			}
+			next := (day + 1) % 7
+			for j, n := range days[next] {
+				if !used[next][j] && n.Start == 0 && n.End > 0 && n.End < w.Start && n.Mode == w.Mode {
+					days[day][k].End = n.End
+					used[next][j] = true
+					break
+				}
+			}
+		}
+	}
+	schedule := Schedule{}
+	for day := range days {
+		for k, w := range days[day] {
+			if used[day][k] {
+				continue
+			}
+			joined := false
+			for i := range schedule {
+				if schedule[i].Start == w.Start && schedule[i].End == w.End && schedule[i].Mode == w.Mode {
+					schedule[i].Days |= w.Days
+					joined = true
+					break
+				}
+			}
+			if !joined {
+				schedule = append(schedule, w)
+			}
+		}
+	}
+	return schedule, nil
+}
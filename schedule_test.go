@@ -0,0 +1,121 @@
+package gotapo_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/KusoKaihatsuSha/gotapo"
+)
+
+func TestRecordPlanRoundTrip(t *testing.T) {
+	_, c := connect(t, true)
+	schedule := gotapo.Schedule{
+		{Days: gotapo.Workdays, Start: gotapo.NewClock(22, 0), End: gotapo.NewClock(6, 0), Mode: gotapo.RecordModeContinuous},
+		{Days: gotapo.Saturday, Start: gotapo.NewClock(23, 30), End: gotapo.NewClock(1, 0), Mode: gotapo.RecordModeContinuous},
+		{Days: gotapo.Weekend, Start: gotapo.NewClock(8, 0), End: gotapo.NewClock(20, 0), Mode: gotapo.RecordModeMotion},
+	}
+	if err := c.SetRecordPlan(gotapo.RecordPlan{Enabled: true, Schedule: schedule}); err != nil {
+		t.Fatal(err)
+	}
+	plan, err := c.RecordPlan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !plan.Enabled {
+		t.Fatal("plan disabled")
+	}
+	if len(plan.Schedule) != len(schedule) {
+		t.Fatalf("schedule %q, want %q", plan.Schedule, schedule)
+	}
+	for _, w := range schedule {
+		found := false
+		for _, v := range plan.Schedule {
+			found = found || reflect.DeepEqual(v, w)
+		}
+		if !found {
+			t.Fatalf("window %q lost in %q", w, plan.Schedule)
+		}
+	}
+}
+
+func TestSetPlanValidate(t *testing.T) {
+	_, c := connect(t, true)
+	bad := []gotapo.Window{
+		{Days: 0, Start: gotapo.NewClock(8, 0), End: gotapo.NewClock(9, 0)},
+		{Days: gotapo.Monday, Start: gotapo.NewClock(24, 0), End: gotapo.NewClock(6, 0)},
+		{Days: gotapo.Monday, Start: gotapo.NewClock(22, 0), End: 0},
+		{Days: gotapo.Monday, Start: gotapo.NewClock(8, 0), End: gotapo.NewClock(8, 0)},
+		{Days: gotapo.Monday, Start: gotapo.NewClock(8, 0), End: gotapo.NewClock(9, 0), Mode: "7"},
+	}
+	for _, w := range bad {
+		if err := c.SetRecordPlan(gotapo.RecordPlan{Schedule: gotapo.Schedule{w}}); err == nil {
+			t.Errorf("record plan %q accepted", w)
+		}
+		if err := c.SetAlarmPlan(gotapo.AlarmPlan{Schedule: gotapo.Schedule{w}}); err == nil {
+			t.Errorf("alarm plan %q accepted", w)
+		}
+	}
+}
+
+func TestParseSchedule(t *testing.T) {
+	s, err := gotapo.ParseSchedule("mon-fri 22:00-06:00; sat,sun 00:00-24:00 motion")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := gotapo.Schedule{
+		{Days: gotapo.Workdays, Start: gotapo.NewClock(22, 0), End: gotapo.NewClock(6, 0)},
+		{Days: gotapo.Weekend, Start: 0, End: gotapo.NewClock(24, 0), Mode: gotapo.RecordModeMotion},
+	}
+	if !reflect.DeepEqual(s, want) {
+		t.Fatalf("schedule %q, want %q", s, want)
+	}
+	if again, err := gotapo.ParseSchedule(s.String()); err != nil || !reflect.DeepEqual(again, s) {
+		t.Fatalf("schedule %q parsed back as %q (%v)", s, again, err)
+	}
+	for _, v := range []string{"24:00-06:00", "mon 24:00-06:00", "mon 08:00-08:00", "mon 08:00-09:00 sometimes"} {
+		if _, err := gotapo.ParseSchedule(v); err == nil {
+			t.Errorf("schedule %q accepted", v)
+		}
+	}
+}
+
+func TestAlarmPlanRoundTrip(t *testing.T) {
+	s, c := connect(t, true)
+	for k, v := range map[string]string{
+		"enabled":        "on",
+		"alarm_plan_num": "3",
+		"alarm_plan_1":   "2200-0600,62",
+		"alarm_plan_2":   "0000-0000,65",
+		"alarm_plan_3":   "1200-1300,0",
+		"alarm_plan_4":   "0100-0200,127",
+	} {
+		s.SetValue("msg_alarm_plan", "chn1_msg_alarm_plan", k, v)
+	}
+	plan, err := c.AlarmPlan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := gotapo.AlarmPlan{Enabled: true, Schedule: gotapo.Schedule{
+		{Days: gotapo.Workdays, Start: gotapo.NewClock(22, 0), End: gotapo.NewClock(6, 0)},
+		{Days: gotapo.Weekend, Start: 0, End: gotapo.NewClock(24, 0)},
+	}}
+	if !reflect.DeepEqual(plan, want) {
+		t.Fatalf("plan %+v, want %+v", plan, want)
+	}
+	if err := c.SetAlarmPlan(plan); err != nil {
+		t.Fatal(err)
+	}
+	if v := s.Value("msg_alarm_plan", "chn1_msg_alarm_plan", "alarm_plan_1"); v != "2200-0600,62" {
+		t.Fatalf("overnight window sent as %v, want not split 2200-0600,62", v)
+	}
+	if v := s.Value("msg_alarm_plan", "chn1_msg_alarm_plan", "alarm_plan_2"); v != "0000-0000,65" {
+		t.Fatalf("whole day sent as %v, want 0000-0000,65", v)
+	}
+	again, err := c.AlarmPlan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(again, want) {
+		t.Fatalf("plan %+v after set, want %+v", again, want)
+	}
+}
@@ -0,0 +1,139 @@
+package gotapo
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// type set siren
+type sirenSet struct {
+	Method string `json:"method"`
+	Data   struct {
+		Type struct {
+			SirenType     string `json:"siren_type,omitempty"`
+			SirenDuration int    `json:"siren_duration,omitempty"`
+			Status        string `json:"status,omitempty"`
+		} `json:"msg_alarm"`
+	} `json:"params"`
+}
+
+// type siren status and lists return (inside multipleRequest)
+type sirenRet struct {
+	Status        string          `json:"status"`
+	TimeLeft      json.Number     `json:"time_left"`
+	SirenTypeList []string        `json:"siren_type_list"`
+	LightTypeList []string        `json:"light_type_list"`
+	MsgAlarm      json.RawMessage `json:"msg_alarm"`
+}
+
+func sirenTypeListTemplate(values ...any) sirenTypeList {
+	t := sirenTypeList{}
+	t.Method = "getSirenTypeList"
+	return t
+}
+
+func lightTypeListTemplate(values ...any) lightTypeList {
+	t := lightTypeList{}
+	t.Method = "getLightTypeList"
+	return t
+}
+
+func sirenStatusTemplate(values ...any) sirenStatus {
+	t := sirenStatus{}
+	t.Method = "getSirenStatus"
+	return t
+}
+
+func sirenConfigTemplate(values ...any) sirenSet {
+	t := sirenSet{}
+	t.Method = "setSirenConfig"
+	t.Data.Type.SirenType = values[0].(string)
+	t.Data.Type.SirenDuration = values[1].(int)
+	return t
+}
+
+func sirenStatusSetTemplate(values ...any) sirenSet {
+	t := sirenSet{}
+	t.Method = "setSirenStatus"
+	t.Data.Type.Status = values[0].(string)
+	return t
+}
+
+// Read answer about siren. Values may be wrapped in msg_alarm
+func (o *Tapo) getSiren(request any) (*sirenRet, error) {
+	result, err := o.multiple(request)
+	if err != nil {
+		return nil, err
+	}
+	ret := new(sirenRet)
+	if err := json.Unmarshal(result.Result.Responses[0].Result, ret); err != nil {
+		return nil, ErrResponse
+	}
+	if len(ret.MsgAlarm) > 0 {
+		wrapped := new(sirenRet)
+		if err := json.Unmarshal(ret.MsgAlarm, wrapped); err != nil {
+			return nil, ErrResponse
+		}
+		return wrapped, nil
+	}
+	return ret, nil
+}
+
+// SirenTypes list sounds of siren supported by firmware
+func (o *Tapo) SirenTypes() ([]string, error) {
+	ret, err := o.getSiren(sirenTypeListTemplate())
+	if err != nil {
+		return nil, err
+	}
+	return ret.SirenTypeList, nil
+}
+
+// LightTypes list types of alarm light supported by firmware
+func (o *Tapo) LightTypes() ([]string, error) {
+	ret, err := o.getSiren(lightTypeListTemplate())
+	if err != nil {
+		return nil, err
+	}
+	return ret.LightTypeList, nil
+}
+
+// SirenStatus return siren is sounding and time left
+func (o *Tapo) SirenStatus() (bool, time.Duration, error) {
+	ret, err := o.getSiren(sirenStatusTemplate())
+	if err != nil {
+		return false, 0, err
+	}
+	left, _ := strconv.Atoi(ret.TimeLeft.String())
+	return sBool(ret.Status), time.Duration(left) * time.Second, nil
+}
+
+// StartSiren sound siren with type (one of SirenTypes) on duration (rounded to seconds)
+func (o *Tapo) StartSiren(sirenType string, duration time.Duration) error {
+	types, err := o.SirenTypes()
+	if err != nil {
+		return err
+	}
+	known := false
+	for _, v := range types {
+		known = known || v == sirenType
+	}
+	if !known {
+		return fmt.Errorf("gotapo: unknown siren type %q, supported %v", sirenType, types)
+	}
+	if duration < time.Second {
+		return fmt.Errorf("gotapo: siren duration %v less then second", duration)
+	}
+	if _, err := o.multiple(sirenConfigTemplate(sirenType, int(duration.Round(time.Second)/time.Second))); err != nil {
+		return err
+	}
+	_, err = o.multiple(sirenStatusSetTemplate("on"))
+	return err
+}
+
+// StopSiren stop sounding siren
+func (o *Tapo) StopSiren() error {
+	_, err := o.multiple(sirenStatusSetTemplate("off"))
+	return err
+}
@@ -0,0 +1,61 @@
+package gotapo_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSirenTypes(t *testing.T) {
+	_, c := connect(t, true)
+	types, err := c.SirenTypes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(types) == 0 || types[0] != "Alarm" {
+		t.Fatalf("siren types %v", types)
+	}
+	lights, err := c.LightTypes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(lights, []string{"0", "1"}) {
+		t.Fatalf("light types %v", lights)
+	}
+}
+
+func TestStartSirenValidate(t *testing.T) {
+	s, c := connect(t, true)
+	if err := c.StartSiren("Trumpet", 10*time.Second); err == nil {
+		t.Fatal("unknown siren type accepted")
+	}
+	if err := c.StartSiren("Doorbell Tone", 500*time.Millisecond); err == nil {
+		t.Fatal("duration less then second accepted")
+	}
+	if v := s.Value("msg_alarm", "siren", "status"); v != "off" {
+		t.Fatalf("siren %v after bad start, want off", v)
+	}
+}
+
+func TestSirenStartStop(t *testing.T) {
+	s, c := connect(t, true)
+	if err := c.StartSiren("Doorbell Tone", 30*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if v := s.Value("msg_alarm", "siren", "siren_type"); v != "Doorbell Tone" {
+		t.Fatalf("siren type %v, want Doorbell Tone", v)
+	}
+	on, left, err := c.SirenStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !on || left <= 25*time.Second || left > 30*time.Second {
+		t.Fatalf("siren on %v left %v, want on with about 30s", on, left)
+	}
+	if err := c.StopSiren(); err != nil {
+		t.Fatal(err)
+	}
+	if on, left, err = c.SirenStatus(); err != nil || on || left != 0 {
+		t.Fatalf("siren on %v left %v (%v), want off", on, left, err)
+	}
+}
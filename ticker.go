@@ -0,0 +1,113 @@
+package gotapo
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// OSDTicker write text from Text to OSD label every Interval (default 10s).
+// Label is 1 for label_info_1 (default). Label is written only when text
+// changed. Text longer than OSDTextLimit is error (not cut), label keep
+// previous text
+type OSDTicker struct {
+	Label    int
+	Interval time.Duration
+	Text     func() (string, error)
+	// Error get errors of Text and cam. Same error is reported once until
+	// success. Nil - errors are printed
+	Error func(err error)
+	o     *Tapo
+}
+
+// NewOSDTicker make ticker of label_info_1
+func (o *Tapo) NewOSDTicker(interval time.Duration, text func() (string, error)) *OSDTicker {
+	return &OSDTicker{
+		Label:    1,
+		Interval: interval,
+		Text:     text,
+		o:        o,
+	}
+}
+
+// Run update label until cancel of ctx, then return nil. Errors are reported
+// to Error, label is tried again on next tick. Text is not waited after cancel
+// of ctx
+func (t *OSDTicker) Run(ctx context.Context) error {
+	if t.Text == nil {
+		return errors.New("gotapo: OSD ticker without text func")
+	}
+	label := t.Label
+	if label <= 0 {
+		label = 1
+	}
+	interval := t.Interval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	report := t.Error
+	if report == nil {
+		report = func(err error) { p(err) }
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	last, written := "", false
+	lastErr := ""
+	for {
+		text, err := t.text(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err == nil {
+			err = checkOSDText(text)
+		}
+		if err == nil && (!written || text != last) {
+			err = t.write(label, text)
+			if err == nil {
+				last, written = text, true
+			}
+		}
+		switch {
+		case err == nil:
+			lastErr = ""
+		case err.Error() != lastErr:
+			lastErr = err.Error()
+			report(err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Call Text in goroutine and wait it or cancel of ctx
+func (t *OSDTicker) text(ctx context.Context) (string, error) {
+	type result struct {
+		text string
+		err  error
+	}
+	ret := make(chan result, 1)
+	go func() {
+		text, err := t.Text()
+		ret <- result{text, err}
+	}()
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case r := <-ret:
+		return r.text, r.err
+	}
+}
+
+// Write text of label and turn it on. Other settings of OSD are kept
+func (t *OSDTicker) write(label int, text string) error {
+	return t.o.UpdateOSD(func(v *OSD) {
+		for len(v.Labels) < label {
+			v.Labels = append(v.Labels, OSDLabel{})
+		}
+		v.Labels[label-1].Text = text
+		v.Labels[label-1].Enabled = true
+	})
+}
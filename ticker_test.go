@@ -0,0 +1,107 @@
+package gotapo_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/KusoKaihatsuSha/gotapo"
+)
+
+func TestOSDTickerLongText(t *testing.T) {
+	_, c := connect(t, true)
+	long := strings.Repeat("x", gotapo.OSDTextLimit+1)
+	mu := sync.Mutex{}
+	texts := []string{"Temp 20", long}
+	ticker := c.NewOSDTicker(10*time.Millisecond, func() (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		text := texts[0]
+		if len(texts) > 1 {
+			texts = texts[1:]
+		}
+		return text, nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errs := make(chan error, 10)
+	ticker.Error = func(err error) { errs <- err }
+	done := make(chan error, 1)
+	go func() { done <- ticker.Run(ctx) }()
+	select {
+	case err := <-errs:
+		if !strings.Contains(err.Error(), long) {
+			t.Fatalf("error %v, want too long text", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("long text not reported")
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("run %v, want nil on cancel", err)
+	}
+	if len(errs) != 0 {
+		t.Fatalf("same error reported %d times more", len(errs))
+	}
+	v, err := c.GetOSD()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := v.Labels[0]; l.Text != "Temp 20" || !l.Enabled {
+		t.Fatalf("label %+v, want Temp 20 (long text not written)", l)
+	}
+}
+
+func TestOSDTickerCancelDuringText(t *testing.T) {
+	_, c := connect(t, true)
+	started, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	once := sync.Once{}
+	ticker := c.NewOSDTicker(time.Hour, func() (string, error) {
+		once.Do(func() { close(started) })
+		<-release
+		return "late", nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- ticker.Run(ctx) }()
+	<-started
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("run %v, want nil on cancel", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("run wait text after cancel")
+	}
+}
+
+func TestOSDTickerSameText(t *testing.T) {
+	s, c := connect(t, true)
+	before := requestsOf(s, "set")
+	ticks := make(chan struct{}, 100)
+	ticker := c.NewOSDTicker(10*time.Millisecond, func() (string, error) {
+		ticks <- struct{}{}
+		return "Temp 20", nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- ticker.Run(ctx) }()
+	for i := 0; i < 5; i++ {
+		select {
+		case <-ticks:
+		case <-time.After(5 * time.Second):
+			t.Fatal("text not read on tick")
+		}
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("run %v, want nil on cancel", err)
+	}
+	if n := requestsOf(s, "set") - before; n != 1 {
+		t.Fatalf("sets of OSD %d, want 1 for same text", n)
+	}
+}
@@ -0,0 +1,77 @@
+package gotapo
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Trace is one logical request to cam and its response in clear JSON
+// (decrypted from securePassthrough). Secrets are redacted
+type Trace struct {
+	Seq       int
+	Method    string
+	URL       string
+	Encrypted bool
+	Request   json.RawMessage
+	Response  json.RawMessage
+	ErrorCode int
+	Duration  time.Duration
+	Err       error
+}
+
+// String is trace for logs: summary line, request and response
+func (t Trace) String() string {
+	s := fmt.Sprintf("#%d %s %s code=%d %s", t.Seq, t.Method, t.URL, t.ErrorCode, t.Duration.Round(time.Millisecond))
+	if t.Encrypted {
+		s += " (secure)"
+	}
+	if t.Err != nil {
+		s += " err=" + t.Err.Error()
+	}
+	return s + "\n> " + string(t.Request) + "\n< " + string(t.Response)
+}
+
+// WithTrace call fn after every request to cam like
+// WithTrace(func(t Trace) { log.Println(t) })
+func WithTrace(fn func(t Trace)) Option {
+	return func(o *Tapo) {
+		o.trace = fn
+	}
+}
+
+// Send trace of request
+func (o *Tapo) emit(host string, encrypt bool, request, response []byte, err error, duration time.Duration) {
+	o.traceSeq++
+	t := Trace{
+		Seq:       o.traceSeq,
+		Method:    requestMethod(request),
+		URL:       stokPath.ReplaceAllString(host, "stok="+Redacted),
+		Encrypted: encrypt,
+		Request:   redact(request),
+		Duration:  duration,
+	}
+	if err != nil {
+		t.Err = redactedError{err}
+	}
+	if len(response) > 0 {
+		t.Response = redact(response)
+		ret := new(errorRet)
+		json.Unmarshal(response, ret)
+		t.ErrorCode = ret.ErrorCode
+	}
+	o.trace(t)
+}
+
+// Error without stok in text (like url in error of http client)
+type redactedError struct {
+	err error
+}
+
+func (e redactedError) Error() string {
+	return stokPath.ReplaceAllString(e.err.Error(), "stok="+Redacted)
+}
+
+func (e redactedError) Unwrap() error {
+	return e.err
+}
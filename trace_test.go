@@ -0,0 +1,131 @@
+package gotapo_test
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/KusoKaihatsuSha/gotapo"
+	"github.com/KusoKaihatsuSha/gotapo/gotapotest"
+)
+
+var traceStok = regexp.MustCompile(`stok=([^/"\s]*)`)
+
+// Secret fields in JSON of trace with their values
+func traceSecrets(t *testing.T, data json.RawMessage, found map[string][]any) {
+	t.Helper()
+	if len(data) == 0 {
+		return
+	}
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatalf("trace not JSON: %s", data)
+	}
+	var walk func(v any)
+	walk = func(v any) {
+		switch val := v.(type) {
+		case map[string]any:
+			for k, item := range val {
+				switch k {
+				case "password", "digest_passwd", "cnonce", "nonce", "stok":
+					found[k] = append(found[k], item)
+				}
+				walk(item)
+			}
+		case []any:
+			for _, item := range val {
+				walk(item)
+			}
+		}
+	}
+	walk(v)
+}
+
+// Every stok in text is redacted
+func checkStok(t *testing.T, text string) {
+	t.Helper()
+	for _, m := range traceStok.FindAllStringSubmatch(text, -1) {
+		if m[1] != gotapo.Redacted {
+			t.Fatalf("stok not redacted in %q", text)
+		}
+	}
+}
+
+// Login secure (nonce, cnonce, digest_passwd) and not (password), request
+// with stok and error of it
+func TestTraceRedact(t *testing.T) {
+	found := map[string][]any{}
+	for _, secure := range []bool{true, false} {
+		var mu sync.Mutex
+		var traces []gotapo.Trace
+		s, c := connect(t, secure, gotapo.WithTrace(func(v gotapo.Trace) {
+			mu.Lock()
+			traces = append(traces, v)
+			mu.Unlock()
+		}))
+		if _, err := c.Info(); err != nil {
+			t.Fatal(err)
+		}
+		s.Fail(gotapotest.FaultDrop, 1)
+		if err := c.SetLed(false); err == nil {
+			t.Fatal("request without answer")
+		}
+		mu.Lock()
+		withErr := false
+		for i, v := range traces {
+			if v.Seq != i+1 {
+				t.Fatalf("seq %d, want %d", v.Seq, i+1)
+			}
+			traceSecrets(t, v.Request, found)
+			traceSecrets(t, v.Response, found)
+			checkStok(t, v.URL)
+			checkStok(t, string(v.Request))
+			checkStok(t, string(v.Response))
+			if v.Err != nil {
+				withErr = withErr || strings.Contains(v.Err.Error(), "stok="+gotapo.Redacted)
+				checkStok(t, v.Err.Error())
+			}
+		}
+		mu.Unlock()
+		if !withErr {
+			t.Fatal("error of request with url not in traces")
+		}
+	}
+	for _, k := range []string{"password", "digest_passwd", "cnonce", "nonce", "stok"} {
+		if len(found[k]) == 0 {
+			t.Fatalf("%s not in traces of login", k)
+		}
+		for _, v := range found[k] {
+			if v != gotapo.Redacted {
+				t.Fatalf("%s is %v, want redacted", k, v)
+			}
+		}
+	}
+}
+
+func TestTraceErrorCode(t *testing.T) {
+	var mu sync.Mutex
+	var traces []gotapo.Trace
+	_, c := connect(t, true, gotapo.WithTrace(func(v gotapo.Trace) {
+		mu.Lock()
+		traces = append(traces, v)
+		mu.Unlock()
+	}))
+	if _, err := c.Info(); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(traces) < 2 {
+		t.Fatalf("traces %d, want login and request", len(traces))
+	}
+	if v := traces[0]; v.ErrorCode != gotapotest.ErrorNonce {
+		t.Fatalf("error code of login %d, want %d", v.ErrorCode, gotapotest.ErrorNonce)
+	}
+	last := traces[len(traces)-1]
+	if last.Seq != len(traces) || last.ErrorCode != 0 || !strings.HasPrefix(last.Method, "multipleRequest") {
+		t.Fatalf("last trace %v", last)
+	}
+}